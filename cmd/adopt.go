@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/emmadal/govm/pkg"
+	"github.com/spf13/cobra"
+)
+
+// adoptCmd represents the adopt command
+var adoptCmd = &cobra.Command{
+	Use:   "adopt <path>",
+	Short: "Import an existing Go installation into govm",
+	Example: strings.Join(
+		[]string{
+			"$ govm adopt /usr/local/go",
+		}, "\n",
+	),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("expect one argument")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		in := installer{platform: pkg.HostPlatform(), adopt: true}
+		if err := in.directory.GetDirectories(); err != nil {
+			return err
+		}
+		if err := in.directory.CreateInstallDir(); err != nil {
+			return err
+		}
+
+		// Copy the Go tree into its own folder, like an install from a local archive
+		version, err := in.installLocal(args[0], "")
+		if err != nil {
+			return err
+		}
+		pkg.GreenPrintln(
+			fmt.Sprintf("✅ Adopted go%s from %s. Run 'govm use %s' to switch to it, %s can then be removed", version, args[0], version, args[0]) + "\n",
+		)
+		return nil
+	},
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/emmadal/govm/pkg"
+	"github.com/spf13/cobra"
+)
+
+var (
+	downloadOS   string
+	downloadArch string
+)
+
+// downloadCmd represents the download command
+var downloadCmd = &cobra.Command{
+	Use:   "download",
+	Short: "Download Go versions into the cache without installing them",
+	Example: strings.Join(
+		[]string{
+			"$ govm download 1.22.3",
+			"$ govm download --os darwin --arch arm64 1.22.3",
+			"$ govm download --os windows --arch amd64 stable oldstable",
+		}, "\n",
+	),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("expect at least one argument")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tarball := pkg.Tarball{OS: downloadOS, Arch: downloadArch}
+		directory := pkg.Directory{}
+		config := pkg.Config{}
+		index := pkg.ReleaseIndex{}
+		platform := tarball.Platform()
+
+		// Load the settings
+		if err := config.Load(); err != nil {
+			return err
+		}
+		downloader, err := config.Downloader()
+		if err != nil {
+			return err
+		}
+		tarball.Downloader = downloader
+
+		// Each platform has its own cache directory
+		if err := directory.GetDirectories(); err != nil {
+			return err
+		}
+		cacheDir := directory.PlatformCacheDir(platform)
+		if err := pkg.ValidatePlatform(platform); err != nil {
+			return err
+		}
+
+		if err := index.Fetch(); err != nil {
+			return err
+		}
+		for _, spec := range args {
+			release, err := index.Resolve(spec)
+			if err != nil {
+				return err
+			}
+			goVersion, err := pkg.ParseGoVersion(release.Version)
+			if err != nil {
+				return err
+			}
+			version := goVersion.String()
+
+			// Only download archives published for the platform, with their checksum
+			if err := release.CheckPlatform(platform); err != nil {
+				return fmt.Errorf("%v. Run 'govm ls-remote --platform %s' to list the versions available for %s", err, platform, platform)
+			}
+			tarball.Checksum = release.Archive(platform).SHA256
+			if err := os.MkdirAll(cacheDir, 0755); err != nil {
+				return fmt.Errorf("unable to create cache directory %s", cacheDir)
+			}
+
+			// Lock the version so no other govm process writes the same cache entry
+			lock, err := pkg.LockVersion(version, nil)
+			if err != nil {
+				return err
+			}
+			err = tarball.DownloadGoVersion(version, cacheDir)
+			lock.Release()
+			if err != nil {
+				return err
+			}
+			pkg.GreenPrintln(fmt.Sprintf("✅ go%s for %s cached in %s", version, platform, filepath.Dir(tarball.File.Name())) + "\n")
+		}
+		return nil
+	},
+}
+
+func init() {
+	downloadCmd.Flags().StringVar(&downloadOS, "os", runtime.GOOS, "operating system of the archives, e.g. darwin")
+	downloadCmd.Flags().StringVar(&downloadArch, "arch", runtime.GOARCH, "architecture of the archives, e.g. arm64 or arm for armv6l")
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/emmadal/govm/pkg"
+	"github.com/spf13/cobra"
+)
+
+var execInstall bool
+
+// execCmd represents the exec command
+var execCmd = &cobra.Command{
+	Use:   "exec <command> [args...]",
+	Short: "Run a command with the Go version selected for the current directory",
+	Example: strings.Join(
+		[]string{
+			"$ govm exec go test ./...",
+			"$ govm exec --install golangci-lint run",
+			"$ GOVM_VERSION=1.22 govm exec go build",
+		}, "\n",
+	),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("expect a command to run")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		directory := pkg.Directory{}
+		config := pkg.Config{}
+		if err := directory.GetDirectories(); err != nil {
+			return err
+		}
+		if err := config.Load(); err != nil {
+			return err
+		}
+		install := config.AutoInstall
+		if cmd.Flags().Changed("install") {
+			install = execInstall
+		}
+
+		// Select the Go version from GOVM_VERSION, the project files or the active version
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		selection, err := directory.SelectVersion(wd)
+		if err != nil {
+			return err
+		}
+		version, err := pkg.ResolveOrInstall(selection, install)
+		if err != nil {
+			return err
+		}
+
+		// Put the Go version first on the PATH, for the command and the processes it starts
+		goRoot := filepath.Join(directory.ConfigDir, "go"+version)
+		path := filepath.Join(goRoot, "bin") + string(os.PathListSeparator) + os.Getenv("PATH")
+		if err := os.Setenv("PATH", path); err != nil {
+			return err
+		}
+		if err := os.Setenv("GOVM_VERSION", version); err != nil {
+			return err
+		}
+		command, err := exec.LookPath(args[0])
+		if err != nil {
+			return err
+		}
+		return pkg.ExecCommand(command, args[1:])
+	},
+}
+
+func init() {
+	execCmd.Flags().BoolVar(&execInstall, "install", false, "install the selected Go version when it is missing")
+	execCmd.Flags().SetInterspersed(false)
+}
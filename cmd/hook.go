@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/emmadal/govm/pkg"
+	"github.com/spf13/cobra"
+)
+
+var hookEnv bool
+
+// hookCmd represents the hook command
+var hookCmd = &cobra.Command{
+	Use:       "hook <shell>",
+	Short:     "Print the shell hook switching to the Go version of a project when entering it",
+	ValidArgs: pkg.Shells,
+	Example: strings.Join(
+		[]string{
+			"$ echo 'eval \"$(govm hook bash)\"' >> ~/.bashrc",
+			"$ echo 'eval \"$(govm hook zsh)\"' >> ~/.zshrc",
+			"$ echo 'govm hook fish | source' >> ~/.config/fish/config.fish",
+			"$ Add-Content $PROFILE 'govm hook pwsh | Out-String | Invoke-Expression'",
+		}, "\n",
+	),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("expect one argument")
+		}
+		return pkg.ValidateShell(args[0])
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		shell := args[0]
+		if !hookEnv {
+			script, err := pkg.ShellHook(shell)
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprint(cmd.OutOrStdout(), script)
+			return nil
+		}
+
+		// Print the statements switching the shell to the version of the working directory
+		directory := pkg.Directory{}
+		if err := directory.GetDirectories(); err != nil {
+			return err
+		}
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		vars, notice, err := directory.HookEnv(wd)
+		if err != nil {
+			return err
+		}
+		if notice != "" {
+			_, _ = fmt.Fprintln(os.Stderr, notice)
+		}
+		_, _ = fmt.Fprint(cmd.OutOrStdout(), pkg.FormatEnv(shell, vars))
+		return nil
+	},
+}
+
+func init() {
+	hookCmd.Flags().BoolVar(&hookEnv, "env", false, "print the statements switching the current shell to the Go version of the working directory, run by the hook")
+}
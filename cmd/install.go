@@ -1,86 +1,515 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
-	"strconv"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/emmadal/govm/pkg"
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
 )
 
 const MinVersion = "1.21.0"
 
+var (
+	installVerifySignature bool
+	installKeyring         string
+	installTimeout         time.Duration
+	installIdleTimeout     time.Duration
+	installModuleProxy     bool
+	installUse             string
+	installJobs            int
+	installArchive         string
+	installSHA256          string
+	installOffline         bool
+	installFromSource      string
+	installSourceDir       string
+)
+
+// installer holds the settings shared by the versions installed by one command.
+type installer struct {
+	directory       pkg.Directory
+	downloader      pkg.Downloader
+	proxy           pkg.ModuleProxy
+	platform        string
+	moduleProxy     bool
+	verifySignature bool
+	keyring         string
+	offline         bool
+	// adopt accepts installations of any version, MinVersion only applies to the
+	// versions govm downloads
+	adopt bool
+}
+
+// installLocal installs a Go version from a local archive or extracted Go tree
+// without touching the network, and returns the version.
+func (in *installer) installLocal(path, checksum string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	var version string
+	if info.IsDir() {
+		if checksum != "" {
+			return "", fmt.Errorf("--sha256 only applies to archives")
+		}
+		version, err = pkg.TreeVersion(path)
+	} else {
+		version, err = pkg.ArchiveVersion(path)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// Check if a version is >= MinVersion
+	if !in.adopt && pkg.MustParseGoVersion(version).Compare(pkg.MustParseGoVersion(MinVersion)) < 0 {
+		return "", fmt.Errorf("minimum supported version is %s. Please install a newer version", MinVersion)
+	}
+
+	// Verify the archive against the provided checksum
+	if checksum != "" {
+		if err := pkg.VerifyChecksum(path, checksum); err != nil {
+			return "", err
+		}
+		pkg.GreenPrintln(fmt.Sprintf("🔒 Checksum verified for %s", filepath.Base(path)) + "\n")
+	}
+
+	// Lock the version so no other govm process installs or removes it meanwhile
+	lock, err := pkg.LockVersion(version, nil)
+	if err != nil {
+		return "", err
+	}
+	defer lock.Release()
+
+	// Stage the install, anything it created is removed if a step fails
+	install, err := pkg.NewInstall(in.directory.ConfigDir, version)
+	if err != nil {
+		return "", err
+	}
+	defer install.Rollback()
+
+	tarball := pkg.Tarball{}
+	if info.IsDir() {
+		return version, tarball.InstallDirectory(path, install)
+	}
+
+	// Copy the archive into the cache and install it like a downloaded one
+	file, created, err := pkg.ImportArchive(path, version, in.directory.CacheDir)
+	if err != nil {
+		return "", err
+	}
+	if created {
+		install.AddCacheFile(file)
+	}
+	return version, tarball.InstallVersion(file, install)
+}
+
+// installSource builds a Go version from a branch, tag or commit of the Go repository
+// and returns the name it is installed under, e.g. tip-abc1234.
+func (in *installer) installSource(source pkg.Source, ref string) (string, error) {
+	// Lock the repository so no other govm process fetches into it meanwhile
+	srcLock, err := pkg.LockSource()
+	if err != nil {
+		return "", err
+	}
+	defer srcLock.Release()
+	if err := source.Sync(); err != nil {
+		return "", err
+	}
+	sha, err := source.Resolve(ref)
+	if err != nil {
+		return "", err
+	}
+	minor, err := source.GoMinor(sha)
+	if err != nil {
+		return "", err
+	}
+	version := pkg.SourceBuildName(ref, sha)
+
+	// Lock the version so no other govm process installs or removes it meanwhile
+	lock, err := pkg.LockVersion(version, nil)
+	if err != nil {
+		return "", err
+	}
+	defer lock.Release()
+
+	// A commit is only built once
+	if _, err := os.Stat(filepath.Join(in.directory.ConfigDir, "go"+version)); err == nil {
+		pkg.GreenPrintln(fmt.Sprintf("✅ go%s is already built", version) + "\n")
+		return version, nil
+	}
+	bootstrap, err := pkg.BootstrapToolchain(minor)
+	if err != nil {
+		return "", err
+	}
+
+	// Stage the install, the build is removed if a step fails
+	install, err := pkg.NewInstall(in.directory.ConfigDir, version)
+	if err != nil {
+		return "", err
+	}
+	defer install.Rollback()
+	if err := source.Build(sha, minor, bootstrap, install); err != nil {
+		return "", err
+	}
+	pkg.GreenPrintln(fmt.Sprintf("✅ go%s built from %s", version, ref) + "\n")
+	return version, nil
+}
+
+// install downloads and installs a Go version, reporting its progress on line.
+func (in *installer) install(release pkg.Release, version string, line *pkg.ProgressLine) error {
+	downloader := in.downloader
+	downloader.Progress = line
+	tarball := pkg.Tarball{Downloader: downloader, Offline: in.offline}
+	proxy := in.proxy
+	proxy.Downloader = downloader
+	proxy.Offline = in.offline
+
+	// Lock the version so no other govm process installs or removes it meanwhile
+	lock, err := pkg.LockVersion(version, line)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	// Stage the install, anything it created is removed if a step fails
+	install, err := pkg.NewInstall(in.directory.ConfigDir, version)
+	if err != nil {
+		return err
+	}
+	defer install.Rollback()
+
+	if in.moduleProxy {
+		// Download the toolchain module and install it
+		file, downloaded, err := proxy.DownloadToolchain(version, in.platform, in.directory.CacheDir)
+		if err != nil {
+			return err
+		}
+		if downloaded {
+			install.AddCacheFile(file)
+		}
+		return tarball.InstallToolchain(file, in.platform, install)
+	}
+
+	// Use the checksum published in the release index
+	if err := release.CheckPlatform(in.platform); err != nil {
+		return err
+	}
+	tarball.Checksum = release.Archive(in.platform).SHA256
+
+	// Download the Go version, extracting it into the staging folder at the same time
+	tarball.StagingDir = install.StagingDir
+	if err := tarball.DownloadGoVersion(version, in.directory.CacheDir); err != nil {
+		return err
+	}
+	if tarball.Downloaded {
+		install.AddCacheFile(tarball.File.Name())
+		install.AddCacheFile(tarball.File.Name() + ".asc")
+	}
+
+	// Verify the PGP signature of the archive
+	if in.verifySignature {
+		if err := tarball.CheckSignature(in.keyring); err != nil {
+			return err
+		}
+	}
+
+	// Install the Go version
+	return tarball.InstallVersion(tarball.File.Name(), install)
+}
+
 var installCmd = &cobra.Command{
-	Use:     "install",
-	Short:   "Install a specific go version",
-	Example: strings.Join([]string{"$ govm install 1.21.0"}, "\n"),
+	Use:   "install",
+	Short: "Install one or more go versions",
+	Example: strings.Join(
+		[]string{
+			"$ govm install 1.21.0",
+			"$ govm install 1.22",
+			"$ govm install latest",
+			"$ govm install stable",
+			"$ govm install oldstable",
+			"$ govm install 1.21.13 1.22.8 1.23.2 --use 1.23",
+			"$ govm install --archive ./go1.22.3.linux-amd64.tar.gz --sha256 <checksum>",
+			"$ govm install 1.22 --offline",
+			"$ govm install tip",
+			"$ govm install --from-source release-branch.go1.23",
+		}, "\n",
+	),
 	Args: func(cmd *cobra.Command, args []string) error {
-		if strings.Contains(args[0], "go") {
-			return fmt.Errorf("invalid version format. Please enter a valid version")
+		if cmd.Flags().Changed("archive") && cmd.Flags().Changed("from-source") {
+			return fmt.Errorf("--archive and --from-source cannot be combined")
 		}
-		if len(args) > 1 || len(args) == 0 {
-
-			return fmt.Errorf("expect one argument")
+		if cmd.Flags().Changed("archive") || cmd.Flags().Changed("from-source") {
+			if len(args) > 0 {
+				return fmt.Errorf("expect no arguments with --archive or --from-source")
+			}
+			return nil
+		}
+		if len(args) == 0 {
+			return fmt.Errorf("expect at least one argument")
+		}
+		if len(args) > 1 && slices.ContainsFunc(args, func(arg string) bool { return pkg.NormalizeSpec(arg) == pkg.SpecTip }) {
+			return fmt.Errorf("tip is built from source and cannot be installed with other versions")
 		}
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if len(args[0]) < 6 {
-			return fmt.Errorf("invalid version format. Please enter a valid version")
+		in := installer{platform: pkg.HostPlatform()}
+		config := pkg.Config{}
+
+		// Load the settings, flags take precedence over the config file
+		if err := config.Load(); err != nil {
+			return err
+		}
+		downloader, err := config.Downloader()
+		if err != nil {
+			return err
+		}
+		if installTimeout > 0 {
+			downloader.Timeout = installTimeout
+		}
+		if installIdleTimeout > 0 {
+			downloader.IdleTimeout = installIdleTimeout
+		}
+		in.downloader = downloader
+		in.proxy.Downloader = downloader
+		in.moduleProxy = config.ModuleProxy
+		if cmd.Flags().Changed("module-proxy") {
+			in.moduleProxy = installModuleProxy
+		}
+		in.verifySignature = config.VerifySignature
+		if cmd.Flags().Changed("verify-signature") {
+			in.verifySignature = installVerifySignature
 		}
+		in.keyring = installKeyring
+		if in.keyring == "" {
+			in.keyring = config.Keyring
+		}
+		in.offline = config.Offline
+		if cmd.Flags().Changed("offline") {
+			in.offline = installOffline
+		}
+		if installJobs < 1 {
+			return fmt.Errorf("--jobs must be at least 1")
+		}
+
+		// Install from a local archive or Go tree
+		if installArchive != "" {
+			if err := in.directory.GetDirectories(); err != nil {
+				return err
+			}
+			if err := in.directory.CreateInstallDir(); err != nil {
+				return err
+			}
+			version, err := in.installLocal(installArchive, installSHA256)
+			if err != nil {
+				return err
+			}
+			if installUse == "none" {
+				return nil
+			}
+			return activate(version)
+		}
+
+		// Build from the Go repository
+		ref := installFromSource
+		if ref == "" && pkg.NormalizeSpec(args[0]) == pkg.SpecTip {
+			ref = pkg.SpecTip
+		}
+		if ref != "" {
+			source := pkg.Source{Repo: config.SourceRepo, Dir: config.SourceDir, Offline: in.offline}
+			if installSourceDir != "" {
+				source.Dir = installSourceDir
+			}
+			if err := in.directory.GetDirectories(); err != nil {
+				return err
+			}
+			if err := in.directory.CreateInstallDir(); err != nil {
+				return err
+			}
+			version, err := in.installSource(source, ref)
+			if err != nil {
+				return err
+			}
+			if installUse == "none" {
+				return nil
+			}
+			return activate(version)
+		}
+
+		// Resolve the version specs against the release index or the toolchains of the module proxy,
+		// or against their cached copy when offline
+		index := pkg.ReleaseIndex{}
+		if in.moduleProxy {
+			if err := in.proxy.Load(); err != nil {
+				return err
+			}
+			in.proxy.Offline = in.offline
 
-		// Check if a version is >= MinVersion
-		if !compareVersions(args[0], MinVersion) {
-			return fmt.Errorf("minimum supported version is %s. Please install a newer version", MinVersion)
+			// Toolchain modules carry no PGP signature, only their checksum is verified
+			if in.verifySignature && cmd.Flags().Changed("verify-signature") {
+				return fmt.Errorf("--verify-signature cannot be combined with --module-proxy, toolchain modules are verified against the checksum database instead")
+			}
+			if in.verifySignature {
+				pkg.BlackPrintln("ℹ️  verify_signature does not apply to the module proxy, toolchain modules are verified against the checksum database instead\n")
+			}
+			if index.Releases, err = in.proxy.Releases(in.platform); err != nil {
+				return err
+			}
+		} else if err := index.Load(in.offline); err != nil {
+			// Before a release index was cached, exact versions are installed from their cached archive
+			if !errors.Is(err, pkg.ErrNoCachedIndex) {
+				return err
+			}
+			dir := pkg.Directory{}
+			if dirErr := dir.GetDirectories(); dirErr != nil {
+				return dirErr
+			}
+			releases, cacheErr := pkg.CachedArchiveReleases(args, dir.CacheDir)
+			if cacheErr != nil {
+				return err
+			}
+			pkg.BlackPrintln("ℹ️  No cached release index, installing the cached archives without checksum verification\n")
+			index.Releases = releases
 		}
+		var releases []pkg.Release
+		var versions []string
+		for _, spec := range args {
+			release, err := index.Resolve(spec)
+			if err != nil {
+				return err
+			}
+			goVersion, err := pkg.ParseGoVersion(release.Version)
+			if err != nil {
+				return err
+			}
 
-		tarball := pkg.Tarball{}
-		directory := pkg.Directory{}
+			// Check if a version is >= MinVersion
+			if goVersion.Compare(pkg.MustParseGoVersion(MinVersion)) < 0 {
+				return fmt.Errorf("minimum supported version is %s. Please install a newer version", MinVersion)
+			}
+
+			// Specs resolving to the same version are installed once
+			version := goVersion.String()
+			if !slices.Contains(versions, version) {
+				releases = append(releases, release)
+				versions = append(versions, version)
+			}
+		}
+
+		// Choose the version to activate: the only one by default, none when several are installed
+		active := ""
+		switch {
+		case installUse == "none":
+		case installUse != "":
+			requested := pkg.Binary{}
+			for _, version := range versions {
+				requested.Versions = append(requested.Versions, "go"+version)
+			}
+			if active, err = requested.ResolveVersion(installUse); err != nil {
+				return fmt.Errorf("--use %s does not match a version being installed", installUse)
+			}
+		case len(versions) == 1:
+			active = versions[0]
+		}
 
 		// Get the directories
-		if err := directory.GetDirectories(); err != nil {
+		if err := in.directory.GetDirectories(); err != nil {
 			return err
 		}
 
 		// Create the config directory
-		if err := directory.CreateInstallDir(); err != nil {
+		if err := in.directory.CreateInstallDir(); err != nil {
 			return err
 		}
 
-		// Download the Go version
-		if err := tarball.DownloadGoVersion(args[0], directory.CacheDir); err != nil {
-			return err
-		}
+		var installErr error
+		if len(versions) == 1 {
+			if err := in.install(releases[0], versions[0], nil); err != nil {
+				return err
+			}
+		} else {
+			// Install the versions in parallel, one progress line each
+			progress := pkg.NewProgress()
+			results := make([]error, len(versions))
+			g := errgroup.Group{}
+			g.SetLimit(installJobs)
+			for i, version := range versions {
+				line := progress.Line("go" + version)
+				line.Println(pkg.BlackAnsi, "⏸  Queued")
+				g.Go(
+					func() error {
+						err := in.install(releases[i], version, line)
+						if err != nil {
+							line.Println(pkg.RedAnsi, "❌ Failed")
+						} else {
+							line.Println(pkg.GreenAnsi, "✅ Installed")
+						}
+						results[i] = err
+						return nil
+					},
+				)
+			}
+			_ = g.Wait()
 
-		// Install the Go version
-		if err := tarball.InstallVersion(tarball.File.Name(), args[0], directory.ConfigDir); err != nil {
-			return err
+			// Print a summary of the installs
+			failed := 0
+			pkg.BlackPrintln("\n📋 Summary\n")
+			for i, version := range versions {
+				if results[i] != nil {
+					failed++
+					pkg.RedPrintln(fmt.Sprintf("  ❌ go%s: %v", version, results[i]) + "\n")
+				} else {
+					pkg.GreenPrintln(fmt.Sprintf("  ✅ go%s installed", version) + "\n")
+				}
+			}
+			if active != "" && results[slices.Index(versions, active)] != nil {
+				pkg.RedPrintln(fmt.Sprintf("Not switching to go%s since it failed to install", active) + "\n")
+				active = ""
+			}
+			if failed > 0 {
+				installErr = fmt.Errorf("%d of %d versions failed to install", failed, len(versions))
+			}
 		}
 
 		// Export the Go version
-		if err := tarball.UseGoVersion(args[0], directory.ConfigDir); err != nil {
-			return err
+		if active != "" {
+			if err := activate(active); err != nil {
+				return err
+			}
 		}
 
-		return nil
+		return installErr
 	},
 }
 
-func compareVersions(a, b string) bool {
-	aParts := strings.Split(a, ".")
-	bParts := strings.Split(b, ".")
-
-	// Convert version parts to integers
-	for i := range 3 {
-		aNum, _ := strconv.Atoi(aParts[i])
-		bNum, _ := strconv.Atoi(bParts[i])
-
-		if aNum > bNum {
-			return true // it is greater
-		} else if aNum < bNum {
-			return false // a is smaller
-		}
+// activate switches to an installed Go version.
+func activate(version string) error {
+	lock, err := pkg.LockHome()
+	if err != nil {
+		return err
 	}
-	return true // Versions are equal
+	defer lock.Release()
+	tarball := pkg.Tarball{}
+	return tarball.UseGoVersion(version)
+}
+
+func init() {
+	installCmd.Flags().BoolVar(&installVerifySignature, "verify-signature", false, "verify the PGP signature of the archive")
+	installCmd.Flags().StringVar(&installKeyring, "keyring", "", "armored PGP keyring used instead of the embedded Go release key")
+	installCmd.Flags().DurationVar(&installTimeout, "timeout", 0, "overall time allowed for the download (default 30m)")
+	installCmd.Flags().BoolVar(&installModuleProxy, "module-proxy", false, "download the golang.org/toolchain module through GOPROXY")
+	installCmd.Flags().DurationVar(&installIdleTimeout, "idle-timeout", 0, "time allowed without receiving data (default 30s)")
+	installCmd.Flags().StringVar(&installUse, "use", "", "version to switch to once installed, or none (default: the version when only one is installed)")
+	installCmd.Flags().IntVarP(&installJobs, "jobs", "j", 3, "number of versions installed in parallel")
+	installCmd.Flags().StringVar(&installArchive, "archive", "", "install from a local .tar.gz or .zip archive, or an extracted Go tree, without network access")
+	installCmd.Flags().StringVar(&installSHA256, "sha256", "", "expected SHA-256 checksum of the --archive file")
+	installCmd.Flags().BoolVar(&installOffline, "offline", false, "install from cached archives only, resolving versions against the cached release index")
+	installCmd.Flags().StringVar(&installFromSource, "from-source", "", "build a branch, tag or commit of the Go repository with make.bash")
+	installCmd.Flags().StringVar(&installSourceDir, "source-dir", "", "local git checkout of Go to build from instead of the clone kept in ~/.govm/src")
 }
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/emmadal/govm/pkg"
+	"github.com/spf13/cobra"
+)
+
+// linkCmd represents the link command
+var linkCmd = &cobra.Command{
+	Use:   "link <name> <path>",
+	Short: "Register an existing Go installation without copying it",
+	Example: strings.Join(
+		[]string{
+			"$ govm link system /usr/local/go",
+			"$ govm link 1.22.3 /opt/go1.22.3",
+			"$ govm link custom ~/src/go",
+		}, "\n",
+	),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			return fmt.Errorf("expect two arguments")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, path := pkg.NormalizeSpec(args[0]), args[1]
+		directory := pkg.Directory{}
+		if err := directory.GetDirectories(); err != nil {
+			return err
+		}
+		if err := directory.CreateInstallDir(); err != nil {
+			return err
+		}
+
+		// Lock the version so no other govm process installs or removes it meanwhile
+		lock, err := pkg.LockVersion(name, nil)
+		if err != nil {
+			return err
+		}
+		defer lock.Release()
+
+		release, err := pkg.LinkVersion(directory.ConfigDir, name, path)
+		if err != nil {
+			return err
+		}
+		pkg.GreenPrintln(fmt.Sprintf("✅ Linked %s (%s) as go%s. Run 'govm use %s' to switch to it", path, release, name, name) + "\n")
+		return nil
+	},
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/emmadal/govm/pkg"
+	"github.com/spf13/cobra"
+)
+
+var localUnset bool
+
+// localCmd represents the local command
+var localCmd = &cobra.Command{
+	Use:   "local [version]",
+	Short: "Select the Go version of the current directory with a .go-version file",
+	Example: strings.Join(
+		[]string{
+			"$ govm local 1.22",
+			"$ govm local",
+			"$ govm local --unset",
+		}, "\n",
+	),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 1 || (localUnset && len(args) > 0) {
+			return fmt.Errorf("expect at most one argument")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		directory := pkg.Directory{}
+		if err := directory.GetDirectories(); err != nil {
+			return err
+		}
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		file := filepath.Join(wd, pkg.GoVersionFile)
+
+		// Remove the version file of the current directory
+		if localUnset {
+			if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			pkg.GreenPrintln(fmt.Sprintf("✅ Removed %s", file) + "\n")
+			return nil
+		}
+
+		// Show the version selected for the current directory
+		if len(args) == 0 {
+			selection, err := directory.SelectVersion(wd)
+			if err != nil {
+				return err
+			}
+			version, err := pkg.ResolveSelection(selection)
+			if err != nil {
+				return err
+			}
+			pkg.BlackPrintln(fmt.Sprintf("go%s (selected by %s)", version, selection.Source) + "\n")
+			return nil
+		}
+
+		// Only select versions that are installed
+		spec := pkg.NormalizeSpec(args[0])
+		version, err := pkg.ResolveSelection(pkg.VersionSelection{Spec: spec, Source: "the command line"})
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(file, []byte(spec+"\n"), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %v", file, err)
+		}
+		pkg.GreenPrintln(fmt.Sprintf("✅ Selected go%s in %s", version, file) + "\n")
+
+		// The shims select the version of the directory the go command runs in
+		lock, err := pkg.LockHome()
+		if err != nil {
+			return err
+		}
+		defer lock.Release()
+		if err := directory.InstallShims(); err != nil {
+			return err
+		}
+		return directory.AddToPath()
+	},
+}
+
+func init() {
+	localCmd.Flags().BoolVar(&localUnset, "unset", false, "remove the .go-version file of the current directory")
+}
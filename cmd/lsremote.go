@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/emmadal/govm/pkg"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lsRemoteStable   bool
+	lsRemoteMinor    string
+	lsRemotePlatform string
+	lsRemoteOffline  bool
+)
+
+// lsRemoteCmd represents the ls-remote command
+var lsRemoteCmd = &cobra.Command{
+	Use:   "ls-remote",
+	Short: "List Go versions available for download",
+	Example: strings.Join(
+		[]string{
+			"$ govm ls-remote",
+			"$ govm ls-remote --stable",
+			"$ govm ls-remote --minor 1.22 --platform linux-arm64",
+			"$ govm ls-remote --offline",
+		}, "\n",
+	),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 {
+			return fmt.Errorf("expect no arguments")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		index := pkg.ReleaseIndex{}
+		directory := pkg.Directory{}
+
+		if err := directory.GetDirectories(); err != nil {
+			return err
+		}
+		// Load the settings, flags take precedence over the config file
+		config := pkg.Config{}
+		if err := config.Load(); err != nil {
+			return err
+		}
+		offline := config.Offline
+		if cmd.Flags().Changed("offline") {
+			offline = lsRemoteOffline
+		}
+
+		platform := ""
+		if lsRemotePlatform != "" {
+			var err error
+			if platform, err = pkg.ParsePlatform(lsRemotePlatform); err != nil {
+				return err
+			}
+		}
+
+		if err := index.Load(offline); err != nil {
+			return err
+		}
+		index.MarkInstalled(directory.ConfigDir)
+
+		releases := index.Filter(lsRemoteStable, lsRemoteMinor, platform)
+		if len(releases) == 0 {
+			return fmt.Errorf("no Go versions found matching the given filters")
+		}
+
+		// List the oldest release first so the newest ends up next to the prompt
+		slices.SortStableFunc(releases, func(a, b pkg.Release) int {
+			aVersion, _ := pkg.ParseGoVersion(a.Version)
+			bVersion, _ := pkg.ParseGoVersion(b.Version)
+			return aVersion.Compare(bVersion)
+		})
+
+		sb := strings.Builder{}
+		for _, release := range releases {
+			line := "→ " + release.Version
+			if !release.Stable {
+				line += " (unstable)"
+			}
+			if release.Installed {
+				sb.WriteString(pkg.TextGreen(line+" (Installed)") + "\n")
+				continue
+			}
+			sb.WriteString(line + "\n")
+		}
+		pkg.BlackPrintln(sb.String())
+		return nil
+	},
+}
+
+func init() {
+	lsRemoteCmd.Flags().BoolVar(&lsRemoteStable, "stable", false, "only list stable releases")
+	lsRemoteCmd.Flags().StringVar(&lsRemoteMinor, "minor", "", "only list releases of a minor version, e.g. 1.22")
+	lsRemoteCmd.Flags().StringVar(&lsRemotePlatform, "platform", "", "only list releases available for a platform, e.g. linux-arm64")
+	lsRemoteCmd.Flags().BoolVar(&lsRemoteOffline, "offline", false, "list releases from the cached release index")
+}
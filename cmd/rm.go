@@ -13,22 +13,40 @@ import (
 
 // rmCmd represents the rm command
 var rmCmd = &cobra.Command{
-	Use:     "rm",
-	Short:   "Remove a specific Go version",
-	Example: strings.Join([]string{"$ govm rm 1.21.0"}, "\n"),
+	Use:   "rm",
+	Short: "Remove a specific Go version",
+	Example: strings.Join(
+		[]string{
+			"$ govm rm 1.21.0",
+			"$ govm rm oldstable",
+		}, "\n",
+	),
 	Args: func(cmd *cobra.Command, args []string) error {
-		if strings.Contains(args[0], "go") {
-			return fmt.Errorf("invalid version format. Please enter a valid version")
-		}
-		if len(args) > 1 || len(args) == 0 {
+		if len(args) != 1 {
 			return fmt.Errorf("expect one argument")
 		}
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Remove the Go version
+		// Resolve the version spec against the installed versions
 		binary := pkg.Binary{}
-		if err := binary.RemoveGoVersion(args[0]); err != nil {
+		if err := binary.GetAllVersions(); err != nil {
+			return err
+		}
+		version, err := binary.ResolveVersion(args[0])
+		if err != nil {
+			return err
+		}
+
+		// Lock the version so it is not removed while being installed or used
+		lock, err := pkg.LockVersion(version, nil)
+		if err != nil {
+			return err
+		}
+		defer lock.Release()
+
+		// Remove the Go version
+		if err := binary.RemoveGoVersion(version); err != nil {
 			return err
 		}
 		return nil
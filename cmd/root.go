@@ -21,7 +21,7 @@ var initCmd = &cobra.Command{
 }
 
 func init() {
-	initCmd.AddCommand(installCmd, useCmd, listCmd, rmCmd, updateCmd, removeCmd)
+	initCmd.AddCommand(shellInitCmd, hookCmd, installCmd, downloadCmd, useCmd, listCmd, lsRemoteCmd, rmCmd, localCmd, execCmd, linkCmd, adoptCmd, updateCmd, removeCmd)
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/emmadal/govm/pkg"
+	"github.com/spf13/cobra"
+)
+
+// shellInitCmd represents the init command
+var shellInitCmd = &cobra.Command{
+	Use:       "init <shell>",
+	Short:     "Print the shell integration making 'govm use' apply to the current shell",
+	ValidArgs: pkg.Shells,
+	Example: strings.Join(
+		[]string{
+			"$ echo 'eval \"$(govm init bash)\"' >> ~/.bashrc",
+			"$ echo 'eval \"$(govm init zsh)\"' >> ~/.zshrc",
+			"$ echo 'govm init fish | source' >> ~/.config/fish/config.fish",
+			"$ Add-Content $PROFILE 'govm init pwsh | Out-String | Invoke-Expression'",
+		}, "\n",
+	),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("expect one argument")
+		}
+		return pkg.ValidateShell(args[0])
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		directory := pkg.Directory{}
+		if err := directory.GetDirectories(); err != nil {
+			return err
+		}
+		script, err := directory.ShellInit(args[0])
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprint(cmd.OutOrStdout(), script)
+		return nil
+	},
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"github.com/emmadal/govm/internal"
+	"github.com/emmadal/govm/pkg"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -25,6 +26,11 @@ var removeCmd = &cobra.Command{
 		if !confirmed || err != nil {
 			return err
 		}
+		lock, err := pkg.LockHome()
+		if err != nil {
+			return err
+		}
+		defer lock.Release()
 		return internal.Uninstall()
 	},
 }
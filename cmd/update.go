@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"github.com/emmadal/govm/internal"
+	"github.com/emmadal/govm/pkg"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -20,6 +21,11 @@ var updateCmd = &cobra.Command{
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		lock, err := pkg.LockHome()
+		if err != nil {
+			return err
+		}
+		defer lock.Release()
 		return internal.UpdateGovm()
 	},
 }
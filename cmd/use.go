@@ -10,16 +10,33 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	useProject bool
+	useInstall bool
+	useEnv     string
+)
+
 // useCmd represents the use command
 var useCmd = &cobra.Command{
-	Use:     "use",
-	Short:   "Use a specific Go version",
-	Example: strings.Join([]string{"$ govm use 1.21.0"}, "\n"),
+	Use:   "use",
+	Short: "Use a specific Go version",
+	Example: strings.Join(
+		[]string{
+			"$ govm use 1.21.0",
+			"$ govm use 1.22",
+			"$ govm use stable",
+			"$ govm use --project",
+			"$ govm use --project --install",
+		}, "\n",
+	),
 	Args: func(cmd *cobra.Command, args []string) error {
-		if strings.Contains(args[0], "go") {
-			return fmt.Errorf("invalid version format. Please enter a valid version")
+		if useProject {
+			if len(args) > 0 {
+				return fmt.Errorf("expect no arguments with --project")
+			}
+			return nil
 		}
-		if len(args) > 1 || len(args) == 0 {
+		if len(args) != 1 {
 			return fmt.Errorf("expect one argument")
 		}
 		return nil
@@ -28,14 +45,76 @@ var useCmd = &cobra.Command{
 		// Use the Go version
 		tarball := pkg.Tarball{}
 		binary := pkg.Binary{}
+		directory := pkg.Directory{}
+		if err := directory.GetDirectories(); err != nil {
+			return err
+		}
 
-		// Get the cached Go version
-		if err := binary.CachedGoVersion(args[0]); err != nil {
+		// For the govm shell function, stdout only carries the statements applying the switch
+		stdout := os.Stdout
+		if useEnv != "" {
+			if err := pkg.ValidateShell(useEnv); err != nil {
+				return err
+			}
+			os.Stdout = os.Stderr
+			defer func() {
+				os.Stdout = stdout
+			}()
+			tarball.ShellSession = true
+		}
+
+		var version string
+		if useProject {
+			// Select the version of the project of the current directory
+			config := pkg.Config{}
+			if err := config.Load(); err != nil {
+				return err
+			}
+			install := config.AutoInstall
+			if cmd.Flags().Changed("install") {
+				install = useInstall
+			}
+			wd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			selection, found, err := pkg.SelectProjectVersion(wd)
+			if err != nil {
+				return err
+			}
+			if !found {
+				return fmt.Errorf("no .go-version, .tool-versions, go.work or go.mod file selects a Go version in %s or its parents", wd)
+			}
+			if version, err = pkg.ResolveOrInstall(selection, install); err != nil {
+				return err
+			}
+			pkg.BlackPrintln(fmt.Sprintf("%s selected by %s", selection, selection.Source) + "\n")
+		} else {
+			// Resolve the version spec against the installed versions
+			if err := binary.GetAllVersions(); err != nil {
+				return err
+			}
+			resolved, err := binary.ResolveVersion(args[0])
+			if err != nil {
+				return err
+			}
+			version = resolved
+		}
+
+		// Lock the version so it is not removed while switching to it
+		lock, err := pkg.LockVersion(version, nil)
+		if err != nil {
+			return err
+		}
+		defer lock.Release()
+		homeLock, err := pkg.LockHome()
+		if err != nil {
 			return err
 		}
-		
+		defer homeLock.Release()
+
 		// Check if the version exists before proceeding
-		folder := filepath.Join(binary.InstallDir, fmt.Sprintf("go%s", args[0]))
+		folder := filepath.Join(directory.ConfigDir, fmt.Sprintf("go%s", version))
 		fileInfo, err := os.Stat(folder)
 		if err != nil {
 			return err
@@ -43,15 +122,30 @@ var useCmd = &cobra.Command{
 
 		// use the Go version
 		if fileInfo.IsDir() && fileInfo.Size() > 0 {
-			directory := pkg.Directory{}
-			if err := directory.GetDirectories(); err != nil {
-				return err
-			}
 			// to Use the Go version
-			if err := tarball.UseGoVersion(args[0], directory.ConfigDir); err != nil {
+			if err := tarball.UseGoVersion(version); err != nil {
 				return err
 			}
 		}
+		if useEnv != "" {
+			env := pkg.FormatEnv(useEnv, directory.SessionEnv(os.Getenv("PATH")))
+			_, _ = fmt.Fprint(stdout, env)
+		}
 		return nil
 	},
 }
+
+func init() {
+	useCmd.Flags().BoolVar(&useProject, "project", false, "use the Go version selected by the .go-version, go.work or go.mod file of the current directory")
+	useCmd.Flags().BoolVar(&useInstall, "install", false, "with --project, install the selected Go version when it is missing")
+	useCmd.Flags().StringVar(&useEnv, "env", "", "print the statements applying the switch to a bash, zsh, fish or pwsh session, used by the govm shell function")
+
+	// The govm shell function evaluates the output of use --env, help goes to stderr instead
+	help := useCmd.HelpFunc()
+	useCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		if useEnv != "" {
+			cmd.SetOut(os.Stderr)
+		}
+		help(cmd, args)
+	})
+}
@@ -98,6 +98,8 @@ func cleanShellProfile(profilePath string) error {
 		regexp.MustCompile(`# govm installation`),
 		regexp.MustCompile(`export PATH=.*\.local/bin.*`),
 		regexp.MustCompile(`export PATH=.*GOVM_DIR.*versions.*go`),
+		regexp.MustCompile(`export PATH=.*\.govm/versions/go/`),
+		regexp.MustCompile(`\.govm/current/bin.*# added by govm`),
 		regexp.MustCompile(`export GOROOT=`),
 	}
 
@@ -205,7 +207,7 @@ func UninstallConfirm() (bool, error) {
 	var sb strings.Builder
 
 	// Build the confirmation message in memory using strings.Builder
-	fmt.Fprintf(&sb, pkg.TextRed("This will completely remove govm from your system, including:"))
+	fmt.Fprint(&sb, pkg.TextRed("This will completely remove govm from your system, including:"))
 	fmt.Fprintln(&sb, "  - The govm binary")
 	fmt.Fprintln(&sb, "  - All installed Go versions managed by govm")
 	fmt.Fprintln(&sb, "  - All govm configuration files")
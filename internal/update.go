@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"time"
 )
@@ -20,20 +21,22 @@ func getBinaryName() string {
 	return "govm"
 }
 
+// releasePlatforms lists the platforms govm binaries are released for, as built by the release workflow.
+var releasePlatforms = []string{
+	"darwin-amd64", "darwin-arm64",
+	"linux-386", "linux-amd64", "linux-arm", "linux-arm64", "linux-loong64", "linux-ppc64le", "linux-riscv64", "linux-s390x",
+	"windows-386", "windows-amd64", "windows-arm64",
+}
+
 // detectPlatform returns the OS and architecture for download
 func detectPlatform() (string, string, error) {
-	goos := runtime.GOOS
+	goos, arch := runtime.GOOS, runtime.GOARCH
 
-	// Map architecture
-	arch := runtime.GOARCH
-	switch arch {
-	case "amd64", "arm64", "386", "x86_64":
-		// These are already correctly named
-		break
-	default:
-		return "", "", fmt.Errorf("unsupported architecture: %s", arch)
+	// govm binaries are named after GOOS and GOARCH, e.g. linux-arm rather than linux-armv6l
+	platform := goos + "-" + arch
+	if !slices.Contains(releasePlatforms, platform) {
+		return "", "", &pkg.UnsupportedPlatformError{What: "govm", Platform: platform, Supported: releasePlatforms}
 	}
-
 	return goos, arch, nil
 }
 
@@ -3,10 +3,20 @@ package main
 import (
 	"fmt"
 	"github.com/emmadal/govm/cmd"
+	"github.com/emmadal/govm/pkg"
 	"os"
 )
 
 func main() {
+	// Invoked through a go or gofmt shim, run the Go version selected for the working directory
+	if name := pkg.ShimName(os.Args[0]); name != "" {
+		if err := pkg.RunShim(name, os.Args[1:]); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, "govm: "+err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
 	if err := cmd.Execute(); err != nil {
 		_, _ = fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
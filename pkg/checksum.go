@@ -0,0 +1,63 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// FileChecksum returns the hex encoded SHA-256 checksum of a file.
+func FileChecksum(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", fmt.Errorf("failed to read %s: %v", file, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// VerifyChecksum checks that the SHA-256 checksum of file matches expected.
+func VerifyChecksum(file, expected string) error {
+	actual, err := FileChecksum(file)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(actual, strings.TrimSpace(expected)) {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", file, expected, actual)
+	}
+	return nil
+}
+
+// FetchChecksum downloads the expected SHA-256 checksum published next to the archive
+// as <archive>.sha256. It is used when the release index does not provide one.
+func (t *Tarball) FetchChecksum() error {
+	body, err := openURL(t.Url + ".sha256")
+	if err != nil {
+		return fmt.Errorf("failed to fetch checksum for %s: %w", t.Url, err)
+	}
+	defer func() {
+		_ = body.Close()
+	}()
+
+	// The file holds the hex digest, optionally followed by the file name
+	data, err := io.ReadAll(io.LimitReader(body, 1024))
+	if err != nil {
+		return fmt.Errorf("failed to read checksum for %s", t.Url)
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 || len(fields[0]) != sha256.Size*2 {
+		return fmt.Errorf("invalid checksum published for %s", t.Url)
+	}
+	t.Checksum = fields[0]
+	return nil
+}
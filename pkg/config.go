@@ -0,0 +1,99 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Config holds the user settings stored in ~/.govm/config.json.
+type Config struct {
+	// VerifySignature enables PGP signature verification of downloaded archives by default.
+	VerifySignature bool `json:"verify_signature"`
+	// Keyring is an armored PGP keyring used instead of the embedded Go release key.
+	Keyring string `json:"keyring,omitempty"`
+	// DownloadTimeout is the overall time allowed for a download, e.g. "30m".
+	DownloadTimeout string `json:"download_timeout,omitempty"`
+	// IdleTimeout is the time allowed without receiving any data, e.g. "30s".
+	IdleTimeout string `json:"idle_timeout,omitempty"`
+	// Retries is the number of times a failed download is retried, 0 turns retries off.
+	Retries *int `json:"retries,omitempty"`
+	// Mirrors lists the http(s) or file:// base URLs to download from, in order.
+	Mirrors []string `json:"mirrors,omitempty"`
+	// ModuleProxy installs toolchains as golang.org/toolchain modules through GOPROXY.
+	ModuleProxy bool `json:"module_proxy"`
+	// LockTimeout is how long to wait for a lock held by another govm process, e.g. "5m".
+	LockTimeout string `json:"lock_timeout,omitempty"`
+	// Offline installs from cached archives and resolves versions against the cached release index.
+	Offline bool `json:"offline"`
+	// SourceRepo is the git repository Go is built from, https://go.googlesource.com/go by default.
+	SourceRepo string `json:"source_repo,omitempty"`
+	// SourceDir is a local git checkout of Go to build from instead of the clone kept by govm.
+	SourceDir string `json:"source_dir,omitempty"`
+	// AutoInstall installs the Go version a project selects when it is missing.
+	AutoInstall bool `json:"auto_install"`
+}
+
+// Load reads the config file. A missing file leaves the defaults in place.
+func (c *Config) Load() error {
+	dir := Directory{}
+	if err := dir.GetDirectories(); err != nil {
+		return err
+	}
+
+	file := filepath.Join(dir.RootDir, "config.json")
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read config file %s: %v", file, err)
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("invalid config file %s: %v", file, err)
+	}
+	return nil
+}
+
+// Downloader returns a downloader configured with the timeouts and retries of the config.
+func (c *Config) Downloader() (Downloader, error) {
+	d := Downloader{}
+	if c.Retries != nil {
+		switch {
+		case *c.Retries < 0:
+			return d, fmt.Errorf("invalid retries %d in config file", *c.Retries)
+		case *c.Retries == 0:
+			d.Retries = NoRetries
+		default:
+			d.Retries = *c.Retries
+		}
+	}
+	if c.DownloadTimeout != "" {
+		timeout, err := time.ParseDuration(c.DownloadTimeout)
+		if err != nil {
+			return d, fmt.Errorf("invalid download_timeout %q in config file", c.DownloadTimeout)
+		}
+		d.Timeout = timeout
+	}
+	if c.IdleTimeout != "" {
+		timeout, err := time.ParseDuration(c.IdleTimeout)
+		if err != nil {
+			return d, fmt.Errorf("invalid idle_timeout %q in config file", c.IdleTimeout)
+		}
+		d.IdleTimeout = timeout
+	}
+	return d, nil
+}
+
+// GetLockTimeout returns how long to wait for a lock held by another govm process.
+func (c *Config) GetLockTimeout() (time.Duration, error) {
+	if c.LockTimeout == "" {
+		return DefaultLockTimeout, nil
+	}
+	timeout, err := time.ParseDuration(c.LockTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid lock_timeout %q in config file", c.LockTimeout)
+	}
+	return timeout, nil
+}
@@ -0,0 +1,43 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// SetCurrent points ~/.govm/current to an installed Go version, so every shell
+// with ~/.govm/current/bin on its PATH uses it right away.
+func (d *Directory) SetCurrent(version string) error {
+	target := filepath.Join(d.ConfigDir, "go"+version)
+	if _, err := os.Stat(target); err != nil {
+		return fmt.Errorf("go version %s not found", version)
+	}
+
+	// Link under a temporary name, then replace the current link in one step
+	tmp := filepath.Join(d.RootDir, ".current-"+strconv.Itoa(os.Getpid()))
+	_ = os.Remove(tmp)
+	if err := linkDir(target, tmp); err != nil {
+		return fmt.Errorf("failed to link %s: %v", d.CurrentDir, err)
+	}
+	if err := replaceLink(tmp, d.CurrentDir); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to switch %s: %v", d.CurrentDir, err)
+	}
+	return nil
+}
+
+// Current returns the Go version ~/.govm/current points to, e.g. go1.22.3.
+func (d *Directory) Current() (string, error) {
+	target, err := os.Readlink(d.CurrentDir)
+	if err != nil {
+		return "", fmt.Errorf("no active Go version. Switch to one with 'govm use <version>'")
+	}
+	return filepath.Base(target), nil
+}
+
+// CurrentBin returns the bin folder of the active Go version, the one to put on the PATH.
+func (d *Directory) CurrentBin() string {
+	return filepath.Join(d.CurrentDir, "bin")
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package pkg
+
+import "os"
+
+// linkDir creates a symbolic link to the directory target.
+func linkDir(target, link string) error {
+	return os.Symlink(target, link)
+}
+
+// replaceLink atomically moves the link tmp over link.
+func replaceLink(tmp, link string) error {
+	return os.Rename(tmp, link)
+}
@@ -0,0 +1,29 @@
+//go:build windows
+
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// linkDir creates a directory junction to target, which unlike a symbolic link
+// needs neither administrator rights nor developer mode.
+func linkDir(target, link string) error {
+	out, err := exec.Command("cmd", "/c", "mklink", "/J", link, target).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// replaceLink moves the junction tmp over link. A junction cannot be renamed
+// over another one, so the previous junction is removed first.
+func replaceLink(tmp, link string) error {
+	if err := os.Remove(link); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Rename(tmp, link)
+}
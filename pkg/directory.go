@@ -7,8 +7,13 @@ import (
 )
 
 type Directory struct {
+	RootDir   string
 	ConfigDir string
 	CacheDir  string
+	// CurrentDir links to the active Go version, its bin folder is on the PATH
+	CurrentDir string
+	// ShimsDir holds the go and gofmt shims selecting a Go version per directory
+	ShimsDir string
 }
 
 // GetDirectories returns the config and cache directories.
@@ -17,11 +22,24 @@ func (d *Directory) GetDirectories() error {
 	if err != nil {
 		return fmt.Errorf("unable to get home directory")
 	}
-	d.ConfigDir = filepath.Join(homeDir, ".govm", "versions", "go")
-	d.CacheDir = filepath.Join(homeDir, ".govm", ".cache")
+	d.RootDir = filepath.Join(homeDir, ".govm")
+	d.ConfigDir = filepath.Join(d.RootDir, "versions", "go")
+	d.CacheDir = filepath.Join(d.RootDir, ".cache")
+	d.CurrentDir = filepath.Join(d.RootDir, "current")
+	d.ShimsDir = filepath.Join(d.RootDir, "shims")
 	return nil
 }
 
+// PlatformCacheDir returns the cache directory of the archives of a platform.
+// Archives for the current platform are kept at the top of the cache, those
+// of other platforms in a sub directory named after the platform.
+func (d *Directory) PlatformCacheDir(platform string) string {
+	if platform == HostPlatform() {
+		return d.CacheDir
+	}
+	return filepath.Join(d.CacheDir, platform)
+}
+
 // CreateInstallDir creates the config directory.
 func (d *Directory) CreateInstallDir() error {
 	if err := os.MkdirAll(d.ConfigDir, 0755); err != nil {
@@ -0,0 +1,275 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultDownloadTimeout = 30 * time.Minute
+	DefaultIdleTimeout     = 30 * time.Second
+	DefaultRetries         = 5
+	// NoRetries turns retries off, since zero falls back to DefaultRetries
+	NoRetries = -1
+)
+
+// errIdleTimeout is returned when a server stops sending data.
+var errIdleTimeout = errors.New("no data received")
+
+// HTTPStatusError is returned when a server answers with an unexpected status code.
+type HTTPStatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *HTTPStatusError) Error() string {
+	if e.StatusCode == http.StatusNotFound {
+		return fmt.Sprintf("%s not found", e.URL)
+	}
+	return fmt.Sprintf("failed to download %s: HTTP status %d", e.URL, e.StatusCode)
+}
+
+// Downloader fetches large files into a .part file, resuming it with HTTP Range
+// requests and retrying transient errors with an exponential backoff.
+// Zero values fall back to the defaults.
+type Downloader struct {
+	Timeout     time.Duration // overall time allowed for the download, including retries
+	IdleTimeout time.Duration // time allowed without receiving any data
+	Retries     int           // retries after the first attempt, or NoRetries
+	Progress    *ProgressLine // line showing the progress, the console when nil
+}
+
+func (d *Downloader) timeout() time.Duration {
+	if d.Timeout > 0 {
+		return d.Timeout
+	}
+	return DefaultDownloadTimeout
+}
+
+func (d *Downloader) idleTimeout() time.Duration {
+	if d.IdleTimeout > 0 {
+		return d.IdleTimeout
+	}
+	return DefaultIdleTimeout
+}
+
+func (d *Downloader) retries() int {
+	if d.Retries > 0 {
+		return d.Retries
+	}
+	if d.Retries < 0 {
+		return 0
+	}
+	return DefaultRetries
+}
+
+// client returns an HTTP client giving up on servers that send no response
+// headers within the idle timeout.
+func (d *Downloader) client() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = d.idleTimeout()
+	return &http.Client{Transport: transport}
+}
+
+// Download fetches url into dest. The data is written to dest.part, which is
+// renamed to dest only once the download is complete.
+func (d *Downloader) Download(url, dest string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout())
+	defer cancel()
+
+	part := dest + ".part"
+	backoff := time.Second
+	var err error
+	for attempt := 0; attempt <= d.retries(); attempt++ {
+		if attempt > 0 {
+			d.Progress.Println(RedAnsi, fmt.Sprintf("\nDownload interrupted (%v), retrying in %s", err, backoff)+"\n")
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return fmt.Errorf("download of %s timed out after %s", url, d.timeout())
+			}
+			backoff = min(backoff*2, 30*time.Second)
+		}
+
+		err = d.fetch(ctx, url, part)
+		if err == nil {
+			if err := os.Rename(part, dest); err != nil {
+				return fmt.Errorf("failed to move %s into the cache: %v", part, err)
+			}
+			return nil
+		}
+		if !retryable(err) {
+			return err
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("download of %s timed out after %s", url, d.timeout())
+		}
+	}
+	return fmt.Errorf("failed to download %s after %d attempts: %w", url, d.retries()+1, err)
+}
+
+// retryable reports whether a failed attempt is worth retrying: network errors,
+// timeouts, connections closed early and 429 or 5xx responses.
+func retryable(err error) bool {
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	if errors.Is(err, errIdleTimeout) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	// The client wraps every error of a request, such as an unsupported URL scheme
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// fetch performs one download attempt, resuming part if it already holds data.
+func (d *Downloader) fetch(ctx context.Context, url, part string) error {
+	if strings.HasPrefix(url, "file:") {
+		return d.copyLocal(url, part)
+	}
+
+	var offset int64
+	if info, err := os.Stat(part); err == nil {
+		offset = info.Size()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request")
+	}
+	if offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	}
+
+	resp, err := d.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The part file already holds the whole file
+		return nil
+	case resp.StatusCode == http.StatusOK:
+		// The server ignored the range, start over
+		offset = 0
+		flags |= os.O_TRUNC
+	default:
+		return &HTTPStatusError{URL: url, StatusCode: resp.StatusCode}
+	}
+
+	f, err := os.OpenFile(part, flags, 0644)
+	if err != nil {
+		return err
+	}
+
+	total := int64(-1)
+	if resp.ContentLength > 0 {
+		total = offset + resp.ContentLength
+	}
+	bar := d.Progress.NewBar(total)
+	_ = bar.Set64(offset)
+
+	body := newIdleReader(resp.Body, d.idleTimeout(), cancel)
+	_, copyErr := io.Copy(io.MultiWriter(bar, f), body)
+	body.stop()
+	if closeErr := f.Close(); copyErr == nil {
+		copyErr = closeErr
+	}
+	if copyErr != nil && body.expired() {
+		return fmt.Errorf("%w for %s", errIdleTimeout, d.idleTimeout())
+	}
+	return copyErr
+}
+
+// copyLocal copies a file mirror entry into part.
+func (d *Downloader) copyLocal(fileURL, part string) error {
+	src, err := openURL(fileURL)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = src.Close()
+	}()
+
+	total := int64(-1)
+	if f, ok := src.(*os.File); ok {
+		if info, err := f.Stat(); err == nil {
+			total = info.Size()
+		}
+	}
+
+	f, err := os.OpenFile(part, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	bar := d.Progress.NewBar(total)
+	_, copyErr := io.Copy(io.MultiWriter(bar, f), src)
+	if closeErr := f.Close(); copyErr == nil {
+		copyErr = closeErr
+	}
+	return copyErr
+}
+
+// idleReader cancels a request when no data has been read for a while.
+type idleReader struct {
+	r       io.Reader
+	timeout time.Duration
+	timer   *time.Timer
+	mu      sync.Mutex
+	fired   bool
+}
+
+func newIdleReader(r io.Reader, timeout time.Duration, cancel context.CancelFunc) *idleReader {
+	ir := &idleReader{r: r, timeout: timeout}
+	ir.timer = time.AfterFunc(timeout, func() {
+		ir.mu.Lock()
+		ir.fired = true
+		ir.mu.Unlock()
+		cancel()
+	})
+	return ir
+}
+
+func (ir *idleReader) Read(p []byte) (int, error) {
+	n, err := ir.r.Read(p)
+	if n > 0 {
+		ir.timer.Reset(ir.timeout)
+	}
+	return n, err
+}
+
+func (ir *idleReader) stop() {
+	ir.timer.Stop()
+}
+
+func (ir *idleReader) expired() bool {
+	ir.mu.Lock()
+	defer ir.mu.Unlock()
+	return ir.fired
+}
@@ -0,0 +1,113 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// quietLine returns a progress line printing nothing.
+func quietLine() *ProgressLine {
+	return (&Progress{out: io.Discard}).Line("test")
+}
+
+func TestRetryable(t *testing.T) {
+	_, schemeErr := http.Get("ftp://example.invalid/go.tar.gz")
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"server error", &HTTPStatusError{URL: "u", StatusCode: http.StatusBadGateway}, true},
+		{"too many requests", &HTTPStatusError{URL: "u", StatusCode: http.StatusTooManyRequests}, true},
+		{"not found", &HTTPStatusError{URL: "u", StatusCode: http.StatusNotFound}, false},
+		{"idle timeout", fmt.Errorf("%w for 30s", errIdleTimeout), true},
+		{"connection closed early", io.ErrUnexpectedEOF, true},
+		{"unsupported scheme", schemeErr, false},
+		{"request construction", fmt.Errorf("failed to create HTTP request"), false},
+		{"cache file", &os.PathError{Op: "open", Path: "go.tar.gz.part", Err: os.ErrPermission}, false},
+	}
+	for _, tt := range tests {
+		if got := retryable(tt.err); got != tt.want {
+			t.Errorf("retryable(%s: %v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestConfigRetries(t *testing.T) {
+	retries := func(n int) *int { return &n }
+	tests := []struct {
+		retries *int
+		want    int
+	}{
+		{nil, DefaultRetries},
+		{retries(0), 0},
+		{retries(2), 2},
+	}
+	for _, tt := range tests {
+		c := Config{Retries: tt.retries}
+		d, err := c.Downloader()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := d.retries(); got != tt.want {
+			t.Errorf("retries with config %v = %d, want %d", tt.retries, got, tt.want)
+		}
+	}
+	if _, err := (&Config{Retries: retries(-1)}).Downloader(); err == nil {
+		t.Error("Downloader accepted negative retries")
+	}
+}
+
+func TestDownloadRetries(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("archive"))
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "go.tar.gz")
+	d := Downloader{Retries: NoRetries, Progress: quietLine()}
+	var statusErr *HTTPStatusError
+	if err := d.Download(server.URL, dest); !errors.As(err, &statusErr) || requests.Load() != 1 {
+		t.Fatalf("Download without retries = %v after %d requests, want a 503 after 1", err, requests.Load())
+	}
+
+	requests.Store(0)
+	d.Retries = 1
+	if err := d.Download(server.URL, dest); err != nil {
+		t.Fatalf("Download with a retry returned error: %v", err)
+	}
+	if data, err := os.ReadFile(dest); err != nil || string(data) != "archive" {
+		t.Errorf("downloaded %q, %v", data, err)
+	}
+}
+
+func TestDownloadHeaderTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	d := Downloader{Timeout: time.Minute, IdleTimeout: 100 * time.Millisecond, Retries: NoRetries, Progress: quietLine()}
+	start := time.Now()
+	if err := d.Download(server.URL, filepath.Join(t.TempDir(), "go.tar.gz")); err == nil {
+		t.Fatal("Download succeeded without response headers")
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Download waited %s for response headers", elapsed)
+	}
+}
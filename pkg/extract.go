@@ -0,0 +1,318 @@
+package pkg
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ExtractArchive extracts a Go .tar.gz or .zip archive into dest, stripping the
+// leading go/ directory like 'tar --strip-components=1'.
+// The progress is shown on line, or on the console when it is nil.
+func ExtractArchive(file, dest string, line *ProgressLine) error {
+	if strings.HasSuffix(file, ".zip") {
+		return extractZip(file, dest, line)
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	bar := line.NewBar(info.Size(), "extracting")
+	return ExtractTarGz(io.TeeReader(f, bar), dest)
+}
+
+// ExtractTarGz extracts a gzip compressed tar stream into dest, stripping the leading go/ directory.
+func ExtractTarGz(r io.Reader, dest string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("invalid gzip archive: %v", err)
+	}
+	defer func() {
+		_ = gz.Close()
+	}()
+
+	// Symlinks are created last so no entry can be written through one
+	var links []symlink
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("invalid tar archive: %v", err)
+		}
+
+		name, ok, err := stripGoDir(hdr.Name)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		target, err := safeJoin(dest, name)
+		if err != nil {
+			return err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeFile(target, tr, hdr.FileInfo().Mode().Perm()); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			links = append(links, symlink{target: target, linkname: hdr.Linkname})
+		case tar.TypeLink:
+			linkName, ok, err := stripGoDir(hdr.Linkname)
+			if err != nil || !ok {
+				return fmt.Errorf("illegal link target %s in archive", hdr.Linkname)
+			}
+			source, err := safeJoin(dest, linkName)
+			if err != nil {
+				return err
+			}
+			if err := os.Link(source, target); err != nil {
+				return err
+			}
+		default:
+			// Skip devices, fifos and other special files
+		}
+	}
+	return makeSymlinks(dest, links)
+}
+
+// extractZip extracts a zip archive into dest, stripping the leading go/ directory.
+func extractZip(file, dest string, line *ProgressLine) error {
+	r, err := zip.OpenReader(file)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", file, err)
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+
+	var links []symlink
+	bar := line.NewBar(-1, "extracting")
+	for _, f := range r.File {
+		_ = bar.Add(1)
+		name, ok, err := stripGoDir(f.Name)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		target, err := safeJoin(dest, name)
+		if err != nil {
+			return err
+		}
+
+		mode := f.Mode()
+		switch {
+		case mode.IsDir():
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case mode&os.ModeSymlink != 0:
+			linkname, err := readZipLink(f)
+			if err != nil {
+				return err
+			}
+			links = append(links, symlink{target: target, linkname: linkname})
+		default:
+			perm := mode.Perm()
+			if perm == 0 {
+				perm = 0644
+			}
+			if err := extractZipFile(f, target, perm); err != nil {
+				return err
+			}
+		}
+	}
+	return makeSymlinks(dest, links)
+}
+
+// stripGoDir removes the leading directory of an archive entry and rejects
+// entries trying to escape it. It reports false for the leading directory itself.
+func stripGoDir(name string) (string, bool, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if strings.HasPrefix(name, "/") {
+		return "", false, fmt.Errorf("illegal absolute path %s in archive", name)
+	}
+	if slices.Contains(strings.Split(name, "/"), "..") {
+		return "", false, fmt.Errorf("illegal file path %s in archive", name)
+	}
+	_, rest, ok := strings.Cut(strings.TrimPrefix(name, "./"), "/")
+	if !ok || strings.Trim(rest, "/") == "" {
+		return "", false, nil
+	}
+	return rest, true, nil
+}
+
+// safeJoin joins an archive entry name to dest and rejects entries escaping it.
+func safeJoin(dest, name string) (string, error) {
+	if filepath.IsAbs(name) || strings.HasPrefix(name, "/") {
+		return "", fmt.Errorf("illegal absolute path %s in archive", name)
+	}
+	target := filepath.Join(dest, filepath.FromSlash(name))
+	if !strings.HasPrefix(target, filepath.Clean(dest)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("illegal file path %s in archive", name)
+	}
+	return target, nil
+}
+
+// symlink is a symbolic link entry of an archive.
+type symlink struct {
+	target   string
+	linkname string
+}
+
+// makeSymlinks creates the symbolic links of an archive, rejecting links pointing outside dest.
+// Each link is resolved through the links created before it, the way the system will resolve it.
+func makeSymlinks(dest string, links []symlink) error {
+	root, err := filepath.EvalSymlinks(dest)
+	if err != nil {
+		return err
+	}
+	for _, link := range links {
+		if err := os.MkdirAll(filepath.Dir(link.target), 0755); err != nil {
+			return err
+		}
+		// A link created inside another link would resolve relative to a different directory
+		parent, err := filepath.EvalSymlinks(filepath.Dir(link.target))
+		if err != nil {
+			return err
+		}
+		if rel, err := filepath.Rel(dest, filepath.Dir(link.target)); err != nil || parent != filepath.Join(root, rel) {
+			return fmt.Errorf("illegal symlink %s -> %s in archive", link.target, link.linkname)
+		}
+		if _, _, err := resolveInside(root, parent, link.linkname, maxLinkHops); err != nil {
+			return fmt.Errorf("illegal symlink %s -> %s in archive", link.target, link.linkname)
+		}
+		if err := os.Symlink(link.linkname, link.target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maxLinkHops bounds the links followed to resolve a link, like the system does.
+const maxLinkHops = 40
+
+// resolveInside resolves the link target path relative to dir, following the links
+// already created, and fails when it leaves root. It reports false when it reaches
+// a missing entry: a link may be created there later, so the rest of the path must
+// not climb back with "..".
+func resolveInside(root, dir, path string, hops int) (string, bool, error) {
+	path = filepath.ToSlash(path)
+	if filepath.IsAbs(path) || strings.HasPrefix(path, "/") || filepath.VolumeName(path) != "" {
+		return "", false, fmt.Errorf("absolute link target %s", path)
+	}
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			if dir == root {
+				return "", false, fmt.Errorf("link target %s leaves the archive", path)
+			}
+			dir = filepath.Dir(dir)
+			continue
+		}
+
+		next := filepath.Join(dir, part)
+		info, err := os.Lstat(next)
+		if err != nil {
+			if slices.Contains(parts[i+1:], "..") {
+				return "", false, fmt.Errorf("link target %s climbs out of a missing entry", path)
+			}
+			return filepath.Join(next, filepath.FromSlash(strings.Join(parts[i+1:], "/"))), false, nil
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			dir = next
+			continue
+		}
+
+		// Follow a link created earlier from the directory holding it
+		if hops == 0 {
+			return "", false, fmt.Errorf("too many links resolving %s", path)
+		}
+		target, err := os.Readlink(next)
+		if err != nil {
+			return "", false, err
+		}
+		resolved, complete, err := resolveInside(root, dir, target, hops-1)
+		if err != nil {
+			return "", false, err
+		}
+		if !complete {
+			if slices.Contains(parts[i+1:], "..") {
+				return "", false, fmt.Errorf("link target %s climbs out of a missing entry", path)
+			}
+			return filepath.Join(resolved, filepath.FromSlash(strings.Join(parts[i+1:], "/"))), false, nil
+		}
+		dir = resolved
+	}
+	return dir, true, nil
+}
+
+// readZipLink reads the target of a symbolic link stored in a zip archive.
+func readZipLink(f *zip.File) (string, error) {
+	r, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+	data, err := io.ReadAll(io.LimitReader(r, 4096))
+	return string(data), err
+}
+
+// writeFile writes the content of r to target with the given permissions.
+func writeFile(target string, r io.Reader, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	_, copyErr := io.Copy(dst, r)
+	if closeErr := dst.Close(); copyErr == nil {
+		copyErr = closeErr
+	}
+	return copyErr
+}
+
+// extractZipFile writes a single zip entry to target.
+func extractZipFile(f *zip.File, target string, perm os.FileMode) error {
+	src, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = src.Close()
+	}()
+	return writeFile(target, src, perm)
+}
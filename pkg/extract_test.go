@@ -0,0 +1,294 @@
+package pkg
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// entry is a file, directory or symbolic link of a test archive.
+type entry struct {
+	name     string
+	body     string
+	linkname string
+	dir      bool
+}
+
+// tarGz builds a gzip compressed tar archive of entries.
+func tarGz(t *testing.T, entries []entry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0644, Typeflag: tar.TypeReg, Size: int64(len(e.body))}
+		switch {
+		case e.dir:
+			hdr.Typeflag, hdr.Mode, hdr.Size = tar.TypeDir, 0755, 0
+		case e.linkname != "":
+			hdr.Typeflag, hdr.Linkname, hdr.Mode, hdr.Size = tar.TypeSymlink, e.linkname, 0777, 0
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// zipFile writes a zip archive of entries and returns its path.
+func zipFile(t *testing.T, entries []entry) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "go.zip")
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for _, e := range entries {
+		hdr := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		body := e.body
+		switch {
+		case e.dir:
+			hdr.SetMode(os.ModeDir | 0755)
+		case e.linkname != "":
+			hdr.SetMode(os.ModeSymlink | 0777)
+			body = e.linkname
+		default:
+			hdr.SetMode(0644)
+		}
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestStripGoDir(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		ok      bool
+		wantErr bool
+	}{
+		{name: "go/bin/go", want: "bin/go", ok: true},
+		{name: "./go/src/", want: "src/", ok: true},
+		{name: `go\bin\gofmt.exe`, want: "bin/gofmt.exe", ok: true},
+		{name: "go/"},
+		{name: "go"},
+		{name: "./go"},
+		{name: "/go/bin/go", wantErr: true},
+		{name: "go/../evil", wantErr: true},
+		{name: "../go/bin/go", wantErr: true},
+		{name: "go/bin/../../evil", wantErr: true},
+		{name: `go\..\evil`, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, ok, err := stripGoDir(tt.name)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("stripGoDir(%q) = %q, want an error", tt.name, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want || ok != tt.ok {
+			t.Errorf("stripGoDir(%q) = %q, %v, %v, want %q, %v", tt.name, got, ok, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSafeJoin(t *testing.T) {
+	dest := t.TempDir()
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "bin/go", want: filepath.Join(dest, "bin", "go")},
+		{name: "src/./cmd", want: filepath.Join(dest, "src", "cmd")},
+		{name: "src/../VERSION", want: filepath.Join(dest, "VERSION")},
+		{name: "/etc/passwd", wantErr: true},
+		{name: "../evil", wantErr: true},
+		{name: "bin/../../evil", wantErr: true},
+		{name: ".", wantErr: true},
+		{name: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := safeJoin(dest, tt.name)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("safeJoin(%q) = %q, want an error", tt.name, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("safeJoin(%q) = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+// symlinkTests are archives whose symbolic links are extracted or rejected.
+var symlinkTests = []struct {
+	name    string
+	entries []entry
+	wantErr bool
+}{
+	{
+		name: "links inside the tree",
+		entries: []entry{
+			{name: "go/pkg/tool/vet", body: "vet"},
+			{name: "go/bin/vet", linkname: "../pkg/tool/vet"},
+			{name: "go/s", linkname: "."},
+			{name: "go/t", linkname: "s/pkg/tool/vet"},
+			{name: "go/u", linkname: "s/s/bin/../VERSION"},
+		},
+	},
+	{
+		name: "link through an earlier link",
+		entries: []entry{
+			{name: "go/s", linkname: "."},
+			{name: "go/t", linkname: "s/../evil"},
+		},
+		wantErr: true,
+	},
+	{
+		name: "link through a chain of links",
+		entries: []entry{
+			{name: "go/a/b/file", body: "file"},
+			{name: "go/s", linkname: "a/b/../.."},
+			{name: "go/t", linkname: "s"},
+			{name: "go/u", linkname: "t/../evil"},
+		},
+		wantErr: true,
+	},
+	{
+		name: "link through a link created later",
+		entries: []entry{
+			{name: "go/t", linkname: "s/../evil"},
+			{name: "go/s", linkname: "."},
+		},
+		wantErr: true,
+	},
+	{
+		name: "link through a link to a missing entry",
+		entries: []entry{
+			{name: "go/a", linkname: "m/x"},
+			{name: "go/c", linkname: "a/../../evil"},
+		},
+		wantErr: true,
+	},
+	{
+		name:    "relative link leaving the tree",
+		entries: []entry{{name: "go/bin/go", linkname: "../../evil"}},
+		wantErr: true,
+	},
+	{
+		name:    "absolute link",
+		entries: []entry{{name: "go/bin/go", linkname: "/usr/bin/go"}},
+		wantErr: true,
+	},
+	{
+		name: "link inside a linked directory",
+		entries: []entry{
+			{name: "go/src", linkname: "."},
+			{name: "go/src/l", linkname: "../evil"},
+		},
+		wantErr: true,
+	},
+}
+
+func TestExtractTarGzSymlinks(t *testing.T) {
+	for _, tt := range symlinkTests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := t.TempDir()
+			if err := os.WriteFile(filepath.Join(parent, "evil"), []byte("outside"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			dest := filepath.Join(parent, "dest")
+			if err := os.Mkdir(dest, 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			err := ExtractTarGz(bytes.NewReader(tarGz(t, tt.entries)), dest)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("ExtractTarGz accepted a link leaving the destination")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExtractTarGz returned error: %v", err)
+			}
+			data, err := os.ReadFile(filepath.Join(dest, "t"))
+			if err != nil || string(data) != "vet" {
+				t.Errorf("reading through t = %q, %v, want vet", data, err)
+			}
+		})
+	}
+}
+
+func TestExtractZipSymlinks(t *testing.T) {
+	for _, tt := range symlinkTests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := extractZip(zipFile(t, tt.entries), t.TempDir(), quietLine())
+			if tt.wantErr && err == nil {
+				t.Fatal("extractZip accepted a link leaving the destination")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("extractZip returned error: %v", err)
+			}
+		})
+	}
+}
+
+func TestExtractTarGzPaths(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []entry
+		wantErr bool
+	}{
+		{name: "go tree", entries: []entry{{name: "go/", dir: true}, {name: "go/VERSION", body: "go1.22.3"}}},
+		{name: "parent directory", entries: []entry{{name: "go/../evil", body: "x"}}, wantErr: true},
+		{name: "absolute path", entries: []entry{{name: "/go/evil", body: "x"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := t.TempDir()
+			err := ExtractTarGz(bytes.NewReader(tarGz(t, tt.entries)), dest)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("ExtractTarGz accepted an entry leaving the destination")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExtractTarGz returned error: %v", err)
+			}
+			if data, err := os.ReadFile(filepath.Join(dest, "VERSION")); err != nil || string(data) != "go1.22.3" {
+				t.Errorf("VERSION = %q, %v", data, err)
+			}
+		})
+	}
+}
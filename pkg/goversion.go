@@ -0,0 +1,100 @@
+package pkg
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// goVersionPattern matches every Go release spelling: 1.21.0, 1.20 (no patch
+// before Go 1.21), 1.22rc2 and 1.9beta1. The "go" prefix is removed beforehand.
+var goVersionPattern = regexp.MustCompile(`^(\d+)\.(\d+)(?:\.(\d+)|(rc|beta)(\d+))?$`)
+
+// GoVersion is a parsed Go release version.
+type GoVersion struct {
+	Major  int
+	Minor  int
+	Patch  int
+	Pre    string // "beta", "rc" or empty for a stable release
+	PreNum int
+}
+
+// ParseGoVersion parses a Go release version such as go1.21.0, 1.22rc2, 1.9beta1 or 1.20.
+func ParseGoVersion(version string) (GoVersion, error) {
+	match := goVersionPattern.FindStringSubmatch(NormalizeSpec(version))
+	if match == nil {
+		return GoVersion{}, fmt.Errorf("invalid Go version %q", version)
+	}
+
+	v := GoVersion{Pre: match[4]}
+	v.Major, _ = strconv.Atoi(match[1])
+	v.Minor, _ = strconv.Atoi(match[2])
+	if match[3] != "" {
+		v.Patch, _ = strconv.Atoi(match[3])
+	}
+	if match[5] != "" {
+		v.PreNum, _ = strconv.Atoi(match[5])
+	}
+	return v, nil
+}
+
+// MustParseGoVersion is like ParseGoVersion but panics on an invalid version.
+func MustParseGoVersion(version string) GoVersion {
+	v, err := ParseGoVersion(version)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Stable reports whether v is not a beta or a release candidate.
+func (v GoVersion) Stable() bool {
+	return v.Pre == ""
+}
+
+// MinorLine returns the minor line of v, e.g. 1.22.
+func (v GoVersion) MinorLine() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// Compare returns -1, 0 or +1 depending on whether v is older, equal or newer than o.
+// Betas sort before release candidates, which sort before the stable release.
+func (v GoVersion) Compare(o GoVersion) int {
+	if c := cmp.Compare(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(preRank(v.Pre), preRank(o.Pre)); c != 0 {
+		return c
+	}
+	return cmp.Compare(v.PreNum, o.PreNum)
+}
+
+// preRank orders pre-release kinds.
+func preRank(pre string) int {
+	switch pre {
+	case "beta":
+		return 0
+	case "rc":
+		return 1
+	}
+	return 2
+}
+
+// String returns the version spelled like the Go release, without the "go" prefix.
+func (v GoVersion) String() string {
+	switch {
+	case !v.Stable():
+		return fmt.Sprintf("%d.%d%s%d", v.Major, v.Minor, v.Pre, v.PreNum)
+	case v.Patch == 0 && v.Major == 1 && v.Minor < 21:
+		// Before Go 1.21 the first release of a minor line had no patch number
+		return v.MinorLine()
+	}
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
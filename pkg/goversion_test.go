@@ -0,0 +1,142 @@
+package pkg
+
+import (
+	"testing"
+)
+
+func TestParseGoVersion(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    GoVersion
+		str     string
+		wantErr bool
+	}{
+		{in: "1.21.0", want: GoVersion{Major: 1, Minor: 21}, str: "1.21.0"},
+		{in: "go1.22.3", want: GoVersion{Major: 1, Minor: 22, Patch: 3}, str: "1.22.3"},
+		{in: " Go1.22.3 ", want: GoVersion{Major: 1, Minor: 22, Patch: 3}, str: "1.22.3"},
+		{in: "1.20", want: GoVersion{Major: 1, Minor: 20}, str: "1.20"},
+		{in: "1.20.14", want: GoVersion{Major: 1, Minor: 20, Patch: 14}, str: "1.20.14"},
+		{in: "1.22rc2", want: GoVersion{Major: 1, Minor: 22, Pre: "rc", PreNum: 2}, str: "1.22rc2"},
+		{in: "go1.9beta1", want: GoVersion{Major: 1, Minor: 9, Pre: "beta", PreNum: 1}, str: "1.9beta1"},
+		// From Go 1.21 on, the first release of a minor line has a patch number
+		{in: "1.21", want: GoVersion{Major: 1, Minor: 21}, str: "1.21.0"},
+		{in: "", wantErr: true},
+		{in: "1", wantErr: true},
+		{in: "1.22.x", wantErr: true},
+		{in: "1.22rc", wantErr: true},
+		{in: "1.22.1rc1", wantErr: true},
+		{in: "latest", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseGoVersion(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseGoVersion(%q) = %v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseGoVersion(%q) returned error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseGoVersion(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+		if got.String() != tt.str {
+			t.Errorf("ParseGoVersion(%q).String() = %q, want %q", tt.in, got.String(), tt.str)
+		}
+	}
+}
+
+func TestGoVersionCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.22beta1", "1.22rc1", -1},
+		{"1.22rc1", "1.22rc2", -1},
+		{"1.22rc2", "1.22.0", -1},
+		{"1.22beta2", "1.22.0", -1},
+		{"1.22.0", "1.22.1", -1},
+		{"1.21.13", "1.22rc1", -1},
+		{"1.9.7", "1.10beta1", -1},
+		{"1.20", "1.20.0", 0},
+		{"1.20", "1.20.1", -1},
+		{"1.20.14", "1.21rc2", -1},
+		{"1.22.3", "go1.22.3", 0},
+		{"2.0.0", "1.99.0", 1},
+	}
+	for _, tt := range tests {
+		a, b := MustParseGoVersion(tt.a), MustParseGoVersion(tt.b)
+		if got := a.Compare(b); got != tt.want {
+			t.Errorf("%s.Compare(%s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := b.Compare(a); got != -tt.want {
+			t.Errorf("%s.Compare(%s) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
+
+func TestResolveSpec(t *testing.T) {
+	// The versions of a release index, with the newest release a release candidate
+	var versions []GoVersion
+	for _, v := range []string{
+		"1.23rc1", "1.22.3", "1.22.2", "1.22.0", "1.22rc2", "1.21.10", "1.21.0",
+		"1.21rc2", "1.20.14", "1.20", "1.19beta1", "1.18.10", "1.18",
+	} {
+		versions = append(versions, MustParseGoVersion(v))
+	}
+	sortNewestFirst(versions)
+
+	tests := []struct {
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		{spec: "latest", want: "1.23rc1"},
+		{spec: "LATEST", want: "1.23rc1"},
+		{spec: "stable", want: "1.22.3"},
+		{spec: "oldstable", want: "1.21.10"},
+		{spec: "1.22", want: "1.22.3"},
+		{spec: "go1.22", want: "1.22.3"},
+		{spec: "1.21", want: "1.21.10"},
+		// A minor line with only pre-releases falls back to the newest of them
+		{spec: "1.23", want: "1.23rc1"},
+		{spec: "1.19", want: "1.19beta1"},
+		// Before Go 1.21, 1.20 is a minor spec as well as the first release of the line
+		{spec: "1.20", want: "1.20.14"},
+		{spec: "1.18", want: "1.18.10"},
+		{spec: "1.20.0", want: "1.20"},
+		{spec: "1.22.2", want: "1.22.2"},
+		{spec: "go1.22rc2", want: "1.22rc2"},
+		{spec: "1.21.0", want: "1.21.0"},
+		{spec: "1.22.1", wantErr: true},
+		{spec: "1.24", wantErr: true},
+		{spec: "", wantErr: true},
+		{spec: "newest", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := resolveSpec(tt.spec, versions, GoVersion.Stable)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("resolveSpec(%q) = %s, want an error", tt.spec, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("resolveSpec(%q) returned error: %v", tt.spec, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("resolveSpec(%q) = %s, want %s", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestResolveSpecEmpty(t *testing.T) {
+	for _, spec := range []string{"latest", "stable", "oldstable", "1.22"} {
+		if got, err := resolveSpec(spec, nil, GoVersion.Stable); err == nil {
+			t.Errorf("resolveSpec(%q) with no versions = %s, want an error", spec, got)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// hookStateVar remembers in the shell the project version the hook switched to, as
+// <version>|<stamp>|<GOROOT before the hook>|<file selecting it>. The version is
+// empty when the one selected by the file is not installed.
+const hookStateVar = "GOVM_HOOK"
+
+// ShellHook returns the script switching the Go version of a shell when it enters
+// a project: a zsh chpwd hook, a bash PROMPT_COMMAND, a fish PWD handler or a
+// PowerShell prompt.
+func ShellHook(shell string) (string, error) {
+	if err := ValidateShell(shell); err != nil {
+		return "", err
+	}
+	name := map[string]string{"bash": "hook.bash", "zsh": "hook.zsh", "fish": "hook.fish", "pwsh": "hook.ps1"}[shell]
+	script, err := shellScripts.ReadFile("shells/" + name)
+	if err != nil {
+		return "", err
+	}
+	return string(script), nil
+}
+
+// hookState returns the state the hook records for a version selected by file, with
+// the GOROOT of the shell before the hook changed it.
+func (d *Directory) hookState(version, goRoot, file string) string {
+	return strings.Join([]string{version, d.hookStamp(version, file), goRoot, file}, "|")
+}
+
+// parseHookState splits a state recorded by the hook.
+func parseHookState(state string) (version, stamp, goRoot, file string) {
+	parts := strings.SplitN(state, "|", 4)
+	for len(parts) < 4 {
+		parts = append(parts, "")
+	}
+	return parts[0], parts[1], parts[2], parts[3]
+}
+
+// hookStamp returns the modification time of the version file. The one of the
+// versions folder is added when the version is not installed, so the hook tries
+// again once a version is installed.
+func (d *Directory) hookStamp(version, file string) string {
+	stamp := ""
+	if info, err := os.Stat(file); err == nil {
+		stamp = strconv.FormatInt(info.ModTime().UnixNano(), 10)
+	}
+	if version == "" {
+		if info, err := os.Stat(d.ConfigDir); err == nil {
+			stamp += "+" + strconv.FormatInt(info.ModTime().UnixNano(), 10)
+		}
+	}
+	return stamp
+}
+
+// HookEnv returns the environment changes switching a shell in dir to the Go version
+// of its project, or back to the active version once it leaves the project, with a
+// short notice describing the switch. Nothing changes while the shell moves within a
+// project whose version file is unchanged, so the hook costs little more than
+// looking for that file.
+func (d *Directory) HookEnv(dir string) ([]EnvVar, string, error) {
+	previous := os.Getenv(hookStateVar)
+	previousVersion, stamp, goRoot, file := parseHookState(previous)
+	if previous == "" {
+		goRoot = os.Getenv("GOROOT")
+	}
+	selection, found, err := SelectProjectVersion(dir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Leaving a project, use the active version again
+	if !found {
+		if previous == "" {
+			return nil, "", nil
+		}
+		notice := "govm: no project Go version"
+		if current, err := d.Current(); err == nil {
+			notice = "govm: using " + current
+		}
+		return append(d.projectEnv("", goRoot), EnvVar{Name: hookStateVar}), notice, nil
+	}
+
+	// Same project and version file, nothing to do. A version that is not installed
+	// was reported when entering the project.
+	if previous != "" && file == selection.Source && stamp == d.hookStamp(previousVersion, file) {
+		if previousVersion == "" {
+			return nil, "", nil
+		}
+		if _, err := os.Stat(filepath.Join(d.ConfigDir, "go"+previousVersion)); err == nil {
+			return nil, "", nil
+		}
+	}
+
+	version, err := ResolveSelection(selection)
+	if err != nil {
+		// Do not keep the version of the previous project
+		vars := append(d.projectEnv("", goRoot), EnvVar{Name: hookStateVar, Value: d.hookState("", goRoot, selection.Source)})
+		return vars, "govm: " + err.Error(), nil
+	}
+	vars := append(d.projectEnv(version, goRoot), EnvVar{Name: hookStateVar, Value: d.hookState(version, goRoot, selection.Source)})
+	return vars, fmt.Sprintf("govm: using go%s (%s from %s)", version, selection, selection.Source), nil
+}
+
+// projectEnv returns the environment changes putting a Go version first on the PATH
+// and setting GOROOT to it, or undoing them when version is empty: GOROOT is then
+// restored to goRoot, the one of the shell before the hook changed it.
+func (d *Directory) projectEnv(version, goRoot string) []EnvVar {
+	var dirs []string
+	if version != "" {
+		goRoot = filepath.Join(d.ConfigDir, "go"+version)
+		dirs = append(dirs, filepath.Join(goRoot, "bin"))
+	}
+	for _, entry := range filepath.SplitList(os.Getenv("PATH")) {
+		if entry != "" && !d.isVersionDir(entry) {
+			dirs = append(dirs, entry)
+		}
+	}
+	return []EnvVar{{Name: "PATH", Value: strings.Join(dirs, string(os.PathListSeparator))}, {Name: "GOROOT", Value: goRoot}}
+}
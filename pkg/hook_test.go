@@ -0,0 +1,69 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// applyEnv sets the environment variables a hook returned, as the shell would.
+func applyEnv(t *testing.T, vars []EnvVar) {
+	t.Helper()
+	for _, v := range vars {
+		t.Setenv(v.Name, v.Value)
+	}
+}
+
+func TestHookEnv(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+	t.Setenv("GOROOT", "/opt/go")
+	t.Setenv(hookStateVar, "")
+	dir := Directory{}
+	if err := dir.GetDirectories(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir.ConfigDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	project := t.TempDir()
+	if err := os.WriteFile(filepath.Join(project, GoVersionFile), []byte("1.22.3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Entering a project whose version is not installed is reported once
+	vars, notice, err := dir.HookEnv(project)
+	if err != nil || notice == "" {
+		t.Fatalf("HookEnv entering the project = %q, %v, want a notice", notice, err)
+	}
+	applyEnv(t, vars)
+	if vars, notice, err := dir.HookEnv(project); err != nil || notice != "" || len(vars) != 0 {
+		t.Errorf("HookEnv within the project = %v, %q, %v, want no change", vars, notice, err)
+	}
+
+	// The project switches to its version once it is installed
+	if err := os.Mkdir(filepath.Join(dir.ConfigDir, "go1.22.3"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	vars, _, err = dir.HookEnv(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	applyEnv(t, vars)
+	if goRoot := os.Getenv("GOROOT"); goRoot != filepath.Join(dir.ConfigDir, "go1.22.3") {
+		t.Errorf("GOROOT in the project = %q", goRoot)
+	}
+
+	// Leaving the project restores the GOROOT of the shell
+	vars, _, err = dir.HookEnv(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	applyEnv(t, vars)
+	if goRoot := os.Getenv("GOROOT"); goRoot != "/opt/go" {
+		t.Errorf("GOROOT after leaving the project = %q, want /opt/go", goRoot)
+	}
+	if state := os.Getenv(hookStateVar); state != "" {
+		t.Errorf("%s after leaving the project = %q", hookStateVar, state)
+	}
+}
@@ -0,0 +1,219 @@
+package pkg
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Install is the transaction installing a Go version: the version is extracted
+// into a hidden staging folder next to the installed versions, validated, and
+// renamed into place. Until it is committed, Rollback removes everything the
+// install created, including the archives it added to the cache.
+type Install struct {
+	Version    string
+	VersionDir string
+	StagingDir string
+	// Release is the version the toolchain reports, e.g. devel go1.24-abc1234 for a
+	// source build. It defaults to go<Version>.
+	Release    string
+	cacheFiles []string
+	committed  bool
+}
+
+// stagingPrefix prefixes the staging folders of the installs in progress.
+const stagingPrefix = ".staging-go"
+
+// NewInstall starts the install of a Go version into versionDir. The caller holds
+// the lock of the version.
+func NewInstall(versionDir, version string) (*Install, error) {
+	removeStaleStaging(versionDir, version)
+	staging, err := os.MkdirTemp(versionDir, stagingPrefix+version+"-")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create staging folder in %s", versionDir)
+	}
+	return &Install{Version: version, VersionDir: versionDir, StagingDir: staging}, nil
+}
+
+// removeStaleStaging removes the staging folders left in versionDir by the installs
+// that were killed. The folder of an install in progress is kept, it is the one of a
+// version locked by another govm process.
+func removeStaleStaging(versionDir, version string) {
+	entries, err := os.ReadDir(versionDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name, ok := strings.CutPrefix(entry.Name(), stagingPrefix)
+		if !ok || !entry.IsDir() {
+			continue
+		}
+		// The staging folder name ends with the random suffix of os.MkdirTemp
+		i := strings.LastIndex(name, "-")
+		if i <= 0 {
+			continue
+		}
+		// The caller holds the lock of version, none of its installs is in progress
+		if staged := name[:i]; staged == version || !versionLocked(staged) {
+			_ = os.RemoveAll(filepath.Join(versionDir, entry.Name()))
+		}
+	}
+}
+
+// release returns the version the toolchain is expected to report.
+func (i *Install) release() string {
+	if i.Release != "" {
+		return i.Release
+	}
+	return "go" + i.Version
+}
+
+// Folder returns the folder the Go version is installed in.
+func (i *Install) Folder() string {
+	return filepath.Join(i.VersionDir, fmt.Sprintf("go%s", i.Version))
+}
+
+// AddCacheFile records a file the install added to the cache, removed on rollback.
+func (i *Install) AddCacheFile(file string) {
+	i.cacheFiles = append(i.cacheFiles, file)
+}
+
+// Validate checks the staging folder holds the requested Go version.
+func (i *Install) Validate() error {
+	goBin := filepath.Join(i.StagingDir, "bin", "go")
+	if runtime.GOOS == "windows" {
+		goBin += ".exe"
+	}
+	info, err := os.Stat(goBin)
+	if err != nil || !info.Mode().IsRegular() {
+		return fmt.Errorf("invalid go%s install: bin/%s is missing", i.Version, filepath.Base(goBin))
+	}
+
+	f, err := os.Open(filepath.Join(i.StagingDir, "VERSION"))
+	if err != nil {
+		return fmt.Errorf("invalid go%s install: VERSION file is missing", i.Version)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	scanner := bufio.NewScanner(f)
+	scanner.Scan()
+	if found := strings.TrimSpace(scanner.Text()); found != i.release() {
+		return fmt.Errorf("invalid go%s install: VERSION file says %q", i.Version, found)
+	}
+	return nil
+}
+
+// Commit validates and smoke tests the staging folder, then renames it into place.
+// A previous install of the same version is only removed once the new one is in place.
+func (i *Install) Commit() error {
+	if err := i.Validate(); err != nil {
+		return err
+	}
+	if err := i.SmokeTest(); err != nil {
+		return err
+	}
+
+	folder := i.Folder()
+	backup := ""
+	if _, err := os.Lstat(folder); err == nil {
+		backup = i.StagingDir + ".old"
+		if err := os.Rename(folder, backup); err != nil {
+			return fmt.Errorf("unable to move previous install %s: %v", folder, err)
+		}
+	}
+	if err := os.Rename(i.StagingDir, folder); err != nil {
+		if backup != "" {
+			_ = os.Rename(backup, folder)
+		}
+		return fmt.Errorf("unable to move %s to %s: %v", i.StagingDir, folder, err)
+	}
+	i.committed = true
+
+	if backup != "" {
+		_ = os.RemoveAll(backup)
+	}
+	return nil
+}
+
+// Rollback removes the staging folder and the cache files of an install that
+// was not committed. It does nothing once the install is committed.
+func (i *Install) Rollback() {
+	if i.committed {
+		return
+	}
+	_ = os.RemoveAll(i.StagingDir)
+	for _, file := range i.cacheFiles {
+		_ = os.Remove(file)
+	}
+}
+
+// smokeTestTimeout bounds each command run by the smoke test.
+const smokeTestTimeout = time.Minute
+
+// SmokeTest runs 'go version' and 'go env GOROOT' from the staging folder and
+// checks they report the requested version and the staging folder as GOROOT.
+func (i *Install) SmokeTest() error {
+	goBin := filepath.Join(i.StagingDir, "bin", "go")
+	if runtime.GOOS == "windows" {
+		goBin += ".exe"
+	}
+
+	output, err := i.runGo(goBin, "version")
+	if err != nil {
+		return err
+	}
+	// go version go1.22.3 linux/amd64
+	reported := strings.TrimPrefix(strings.TrimSpace(output), "go version ")
+	if !strings.HasPrefix(reported, i.release()+" ") {
+		return i.smokeTestError(goBin+" version", fmt.Errorf("expected %s", i.release()), output)
+	}
+
+	output, err = i.runGo(goBin, "env", "GOROOT")
+	if err != nil {
+		return err
+	}
+	if !sameFile(strings.TrimSpace(output), i.StagingDir) {
+		return i.smokeTestError(goBin+" env GOROOT", fmt.Errorf("expected GOROOT %s", i.StagingDir), output)
+	}
+	return nil
+}
+
+// runGo runs the go command of the staging folder, isolated from the toolchain
+// settings of the environment and of the current module.
+func (i *Install) runGo(goBin string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), smokeTestTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, goBin, args...)
+	cmd.Dir = i.StagingDir
+	cmd.Env = append(os.Environ(), "GOROOT=", "GOTOOLCHAIN=local", "GOFLAGS=")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", i.smokeTestError(goBin+" "+strings.Join(args, " "), err, string(output))
+	}
+	return string(output), nil
+}
+
+// smokeTestError describes a failed smoke test command.
+func (i *Install) smokeTestError(command string, err error, output string) error {
+	hint := ""
+	if errors.Is(err, syscall.ENOEXEC) || strings.Contains(err.Error(), "exec format error") {
+		hint = "\n  hint:    the binary does not match this machine, check the architecture of the archive"
+	} else if errors.Is(err, fs.ErrNotExist) {
+		hint = "\n  hint:    the install is missing files"
+	}
+	return fmt.Errorf(
+		"go%s failed its smoke test and was not activated\n  command: %s\n  error:   %v\n  output:  %s\n  host:    %s/%s%s",
+		i.Version, command, err, strings.TrimSpace(output), runtime.GOOS, runtime.GOARCH, hint,
+	)
+}
@@ -0,0 +1,59 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewInstallRemovesStaleStaging(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+	dir := Directory{}
+	if err := dir.GetDirectories(); err != nil {
+		t.Fatal(err)
+	}
+
+	// An install of go1.21.0 in progress in another process
+	busy, err := LockVersion("1.21.0", quietLine())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy.Release()
+	lock, err := LockVersion("1.22.3", quietLine())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Release()
+
+	staging := map[string]bool{
+		".staging-go1.22.3-123":      false,
+		".staging-go1.20.14-456":     false,
+		".staging-gotip-abc1234-789": false,
+		".staging-go1.21.0-012":      true,
+		"go1.20.14":                  true,
+	}
+	for name := range staging {
+		if err := os.MkdirAll(filepath.Join(dir.ConfigDir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The lock file of a version installed before, not held anymore
+	if err := os.WriteFile(filepath.Join(dir.RootDir, ".locks", "go1.20.14.lock"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	install, err := NewInstall(dir.ConfigDir, "1.22.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer install.Rollback()
+	for name, kept := range staging {
+		if _, err := os.Stat(filepath.Join(dir.ConfigDir, name)); (err == nil) != kept {
+			t.Errorf("%s kept = %v, want %v", name, err == nil, kept)
+		}
+	}
+	if _, err := os.Stat(install.StagingDir); err != nil {
+		t.Errorf("staging folder of the install: %v", err)
+	}
+}
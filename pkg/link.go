@@ -0,0 +1,86 @@
+package pkg
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// reservedNames are the version specs a linked installation cannot be named after.
+var reservedNames = []string{SpecLatest, SpecStable, SpecOldStable, SpecTip}
+
+// ReleaseOf returns the first line of the VERSION file of a Go installation,
+// e.g. go1.22.3 or devel go1.24-abc1234 for a custom build.
+func ReleaseOf(goRoot string) (string, error) {
+	f, err := os.Open(filepath.Join(goRoot, "VERSION"))
+	if err != nil {
+		return "", fmt.Errorf("no VERSION file in %s, is it a Go installation?", goRoot)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	scanner := bufio.NewScanner(f)
+	scanner.Scan()
+	release := strings.TrimSpace(scanner.Text())
+	if !strings.HasPrefix(release, "go") && !strings.HasPrefix(release, "devel ") {
+		return "", fmt.Errorf("unexpected VERSION file %q in %s", release, goRoot)
+	}
+
+	goBin := "go"
+	if runtime.GOOS == "windows" {
+		goBin = "go.exe"
+	}
+	if _, err := os.Stat(filepath.Join(goRoot, "bin", goBin)); err != nil {
+		return "", fmt.Errorf("no bin/%s in %s, is it a Go installation?", goBin, goRoot)
+	}
+	return release, nil
+}
+
+// LinkVersion registers the Go installation at goRoot under name in versionDir,
+// as a symbolic link so the installation is used in place. It returns the
+// version recorded in the VERSION file of the installation.
+func LinkVersion(versionDir, name, goRoot string) (string, error) {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") || slices.Contains(reservedNames, name) {
+		return "", fmt.Errorf("invalid name %q", name)
+	}
+	goRoot, err := filepath.Abs(goRoot)
+	if err != nil {
+		return "", err
+	}
+	release, err := ReleaseOf(goRoot)
+	if err != nil {
+		return "", err
+	}
+
+	// A name spelled like a release must be that release
+	if version, err := ParseGoVersion(name); err == nil && release != "go"+version.String() {
+		return "", fmt.Errorf("%s holds %s, it cannot be linked as go%s", goRoot, release, version)
+	}
+
+	folder := filepath.Join(versionDir, "go"+name)
+	if _, err := os.Lstat(folder); err == nil {
+		return "", fmt.Errorf("go%s is already installed. Remove it first with 'govm rm %s'", name, name)
+	}
+	if err := os.Symlink(goRoot, folder); err != nil {
+		return "", fmt.Errorf("failed to link %s: %v", goRoot, err)
+	}
+	return release, nil
+}
+
+// LinkTarget returns the installation a linked version points to, or false
+// for a version installed by govm.
+func LinkTarget(folder string) (string, bool) {
+	info, err := os.Lstat(folder)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return "", false
+	}
+	target, err := os.Readlink(folder)
+	if err != nil {
+		return "", false
+	}
+	return target, true
+}
@@ -0,0 +1,193 @@
+package pkg
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// parseVersionFile reads the Go version from the first line of a VERSION file, e.g. go1.22.3.
+func parseVersionFile(r io.Reader) (string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Scan()
+	line := strings.TrimSpace(scanner.Text())
+	version, err := ParseGoVersion(line)
+	if err != nil {
+		return "", fmt.Errorf("unexpected VERSION file %q", line)
+	}
+	return version.String(), nil
+}
+
+// ArchiveVersion returns the Go version recorded in the go/VERSION file of a .tar.gz or .zip archive.
+func ArchiveVersion(file string) (string, error) {
+	if strings.HasSuffix(file, ".zip") {
+		r, err := zip.OpenReader(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to open %s: %v", file, err)
+		}
+		defer func() {
+			_ = r.Close()
+		}()
+		for _, f := range r.File {
+			if name, ok, _ := stripGoDir(f.Name); ok && name == "VERSION" {
+				src, err := f.Open()
+				if err != nil {
+					return "", err
+				}
+				defer func() {
+					_ = src.Close()
+				}()
+				return parseVersionFile(src)
+			}
+		}
+		return "", fmt.Errorf("no go/VERSION file in %s", file)
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return "", fmt.Errorf("invalid gzip archive %s: %v", file, err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return "", fmt.Errorf("no go/VERSION file in %s", file)
+		}
+		if err != nil {
+			return "", fmt.Errorf("invalid tar archive %s: %v", file, err)
+		}
+		if name, ok, _ := stripGoDir(hdr.Name); ok && name == "VERSION" {
+			return parseVersionFile(tr)
+		}
+	}
+}
+
+// TreeVersion returns the Go version recorded in the VERSION file of an extracted Go tree.
+func TreeVersion(dir string) (string, error) {
+	f, err := os.Open(filepath.Join(dir, "VERSION"))
+	if err != nil {
+		return "", fmt.Errorf("no VERSION file in %s, is it a Go installation?", dir)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	return parseVersionFile(f)
+}
+
+// ImportArchive copies a local archive of a Go version into cachePath, under the
+// name of the archive Go distributes for the host, e.g. go1.22.3.linux-amd64.tar.gz.
+// An archive already cached under that name is kept, the import is refused when
+// it differs from the local one. It reports whether the cache entry was created
+// by the import.
+func ImportArchive(file, version, cachePath string) (string, bool, error) {
+	tarball := Tarball{}
+	name := fmt.Sprintf("go%s.%s", version, tarball.GetArchWithExt())
+	if ext := strings.TrimPrefix(tarball.GetArchWithExt(), tarball.Platform()); !strings.HasSuffix(file, ext) {
+		return "", false, fmt.Errorf("Go for %s is distributed as a %s archive, %s is not one", tarball.Platform(), ext, file)
+	}
+	dest := filepath.Join(cachePath, name)
+
+	// The archive may already be the cache entry
+	if sameFile(file, dest) {
+		return dest, false, nil
+	}
+	if _, err := os.Stat(dest); err == nil {
+		cached, err := FileChecksum(dest)
+		if err != nil {
+			return "", false, err
+		}
+		if err := VerifyChecksum(file, cached); err != nil {
+			return "", false, fmt.Errorf("%s differs from the go%s archive in the cache %s, remove it from the cache to import another archive", file, version, dest)
+		}
+		return dest, false, nil
+	}
+
+	src, err := os.Open(file)
+	if err != nil {
+		return "", false, err
+	}
+	defer func() {
+		_ = src.Close()
+	}()
+	part := dest + ".part"
+	if err := writeFile(part, src, 0644); err != nil {
+		_ = os.Remove(part)
+		return "", false, fmt.Errorf("failed to copy %s into the cache: %v", file, err)
+	}
+	if err := os.Rename(part, dest); err != nil {
+		return "", false, fmt.Errorf("failed to move %s into the cache: %v", part, err)
+	}
+	return dest, true, nil
+}
+
+// sameFile reports whether two paths point to the same file.
+func sameFile(a, b string) bool {
+	aInfo, aErr := os.Stat(a)
+	bInfo, bErr := os.Stat(b)
+	return aErr == nil && bErr == nil && os.SameFile(aInfo, bInfo)
+}
+
+// InstallDirectory copies an extracted Go tree into the staging folder of an install and commits it.
+func (t *Tarball) InstallDirectory(src string, install *Install) error {
+	t.Downloader.Progress.Println(BlackAnsi, fmt.Sprintf("⏳ Installing go%s from %s", install.Version, src)+"\n")
+
+	if err := CopyTree(src, install.StagingDir); err != nil {
+		return fmt.Errorf("failed to copy %s: %v", src, err)
+	}
+	return install.Commit()
+}
+
+// CopyTree copies the files, directories and symbolic links of src into dest.
+func CopyTree(src, dest string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, 0755)
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				_ = f.Close()
+			}()
+			return writeFile(target, f, info.Mode().Perm())
+		default:
+			// Skip devices, sockets and other special files
+			return nil
+		}
+	})
+}
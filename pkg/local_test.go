@@ -0,0 +1,40 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestImportArchive(t *testing.T) {
+	ext := ".tar.gz"
+	if runtime.GOOS == "windows" {
+		ext = ".zip"
+	}
+	dir, cache := t.TempDir(), t.TempDir()
+	file := filepath.Join(dir, "go-offline"+ext)
+	if err := os.WriteFile(file, []byte("archive"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(cache, "go1.22.3."+HostPlatform()+ext)
+
+	got, created, err := ImportArchive(file, "1.22.3", cache)
+	if err != nil || got != want || !created {
+		t.Fatalf("ImportArchive = %q, %v, %v, want %q, true", got, created, err, want)
+	}
+	// The same archive reuses the cache entry
+	if got, created, err := ImportArchive(file, "1.22.3", cache); err != nil || got != want || created {
+		t.Errorf("ImportArchive of the cached archive = %q, %v, %v, want %q, false", got, created, err, want)
+	}
+	// A different archive is refused and the cache entry kept
+	if err := os.WriteFile(file, []byte("other"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ImportArchive(file, "1.22.3", cache); err == nil {
+		t.Error("ImportArchive overwrote the cache entry with a different archive")
+	}
+	if data, err := os.ReadFile(want); err != nil || string(data) != "archive" {
+		t.Errorf("cache entry = %q, %v, want archive", data, err)
+	}
+}
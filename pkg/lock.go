@@ -0,0 +1,163 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultLockTimeout is how long govm waits for a lock held by another govm process.
+const DefaultLockTimeout = 5 * time.Minute
+
+// lockRetryInterval is how often a busy lock is tried again.
+const lockRetryInterval = 100 * time.Millisecond
+
+// Lock is an advisory lock on a file, held across processes until it is released.
+// The file records the PID of the holder. The operating system releases the lock
+// when the holder exits, so a crashed process never leaves a stale lock behind.
+type Lock struct {
+	Path string
+	file *os.File
+}
+
+// AcquireLock locks path, waiting up to timeout while another process holds it.
+// The wait is reported on line, or on the console when it is nil.
+func AcquireLock(path, name string, timeout time.Duration, line *ProgressLine) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("unable to create lock directory %s", filepath.Dir(path))
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open lock file %s: %v", path, err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	waiting := false
+	for {
+		locked, err := tryLockFile(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("unable to lock %s: %v", path, err)
+		}
+		if locked {
+			break
+		}
+		if time.Now().After(deadline) {
+			_ = f.Close()
+			return nil, fmt.Errorf("%s is locked by govm process %s, gave up after %s", name, lockHolder(path), timeout)
+		}
+		if !waiting {
+			waiting = true
+			line.Println(BlackAnsi, fmt.Sprintf("⏳ Waiting for %s, locked by govm process %s", name, lockHolder(path))+"\n")
+		}
+		time.Sleep(lockRetryInterval)
+	}
+
+	// Record the holder for the processes waiting on the lock
+	if err := f.Truncate(0); err == nil {
+		_, _ = f.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0)
+	}
+	return &Lock{Path: path, file: f}, nil
+}
+
+// lockHolder returns the PID recorded in a lock file.
+func lockHolder(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil || strings.TrimSpace(string(data)) == "" {
+		return "(unknown PID)"
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// Release releases the lock. The lock file is kept, removing it would let two
+// processes lock different files under the same name.
+func (l *Lock) Release() {
+	if l == nil || l.file == nil {
+		return
+	}
+	_ = unlockFile(l.file)
+	_ = l.file.Close()
+	l.file = nil
+}
+
+// lockTimeout returns the lock timeout of the config file.
+func lockTimeout() (time.Duration, error) {
+	config := Config{}
+	if err := config.Load(); err != nil {
+		return 0, err
+	}
+	return config.GetLockTimeout()
+}
+
+// LockHome locks the govm home directory. It guards the state shared by all
+// versions, such as the active version and the govm binary itself.
+func LockHome() (*Lock, error) {
+	dir := Directory{}
+	if err := dir.GetDirectories(); err != nil {
+		return nil, err
+	}
+	timeout, err := lockTimeout()
+	if err != nil {
+		return nil, err
+	}
+	return AcquireLock(filepath.Join(dir.RootDir, ".lock"), dir.RootDir, timeout, nil)
+}
+
+// LockVersion locks a Go version, guarding its install folder and cached archives.
+// When both are needed, the version lock is taken before the home lock.
+// The wait for the lock is reported on line, or on the console when it is nil.
+func LockVersion(version string, line *ProgressLine) (*Lock, error) {
+	dir := Directory{}
+	if err := dir.GetDirectories(); err != nil {
+		return nil, err
+	}
+	timeout, err := lockTimeout()
+	if err != nil {
+		return nil, err
+	}
+	name := fmt.Sprintf("go%s", version)
+	return AcquireLock(filepath.Join(dir.RootDir, ".locks", name+".lock"), name, timeout, line)
+}
+
+// versionLocked reports whether another govm process holds the lock of a Go version.
+// A version whose lock file cannot be opened is reported as locked.
+func versionLocked(version string) bool {
+	dir := Directory{}
+	if err := dir.GetDirectories(); err != nil {
+		return true
+	}
+	f, err := os.OpenFile(filepath.Join(dir.RootDir, ".locks", fmt.Sprintf("go%s.lock", version)), os.O_RDWR, 0)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	locked, err := tryLockFile(f)
+	if err != nil || !locked {
+		return true
+	}
+	_ = unlockFile(f)
+	return false
+}
+
+// LockSource locks the Go source repository govm clones for source builds.
+func LockSource() (*Lock, error) {
+	dir := Directory{}
+	if err := dir.GetDirectories(); err != nil {
+		return nil, err
+	}
+	timeout, err := lockTimeout()
+	if err != nil {
+		return nil, err
+	}
+	return AcquireLock(filepath.Join(dir.RootDir, ".locks", "src.lock"), "the Go source repository", timeout, nil)
+}
@@ -2,162 +2,242 @@ package pkg
 
 import (
 	"fmt"
-	"github.com/schollz/progressbar/v3"
-	"io"
-	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"runtime"
-	"time"
+	"slices"
+	"strings"
 )
 
 type Tarball struct {
 	Url  string
 	File *os.File
-	Arch string
+	// OS and Arch select the platform of the archive, the current one when empty
+	OS         string
+	Arch       string
+	Checksum   string
+	Downloader Downloader
+	// StagingDir receives the archive content while it downloads, when set
+	StagingDir string
+	// Extracted reports whether the archive was extracted into StagingDir during the download
+	Extracted bool
+	// Downloaded reports whether the archive was downloaded rather than taken from the cache
+	Downloaded bool
+	// Offline only uses the archives already in the cache
+	Offline bool
+	// ShellSession reports the govm shell function applies the switch to the calling shell
+	ShellSession bool
 }
 
-// GetArchWithExt returns the architecture and extension for the current OS.
-func (t *Tarball) GetArchWithExt() string {
-	var ext string
-	os := runtime.GOOS
-	if os == "windows" {
-		ext = "zip"
-	} else {
-		ext = "tar.gz"
+// Platform returns the distribution platform of the archive, e.g. linux-armv6l.
+func (t *Tarball) Platform() string {
+	goos, goarch := t.OS, t.Arch
+	if goos == "" {
+		goos = runtime.GOOS
 	}
-	return fmt.Sprintf("%s-%s.%s", runtime.GOOS, runtime.GOARCH, ext)
+	if goarch == "" {
+		goarch = runtime.GOARCH
+	}
+	return DistPlatform(goos, goarch)
 }
 
-// GetURL returns the download URL for the current OS.
-func (t *Tarball) GetURL(version string) {
+// GetArchWithExt returns the platform and extension of the archive, e.g. linux-amd64.tar.gz.
+// Go is distributed as a .zip for Windows and as a .tar.gz for other systems.
+func (t *Tarball) GetArchWithExt() string {
 	var ext string
-	if runtime.GOOS == "windows" {
+	if strings.HasPrefix(t.Platform(), "windows-") {
 		ext = "zip"
 	} else {
 		ext = "tar.gz"
 	}
-	arch := fmt.Sprintf("%s-%s", runtime.GOOS, runtime.GOARCH)
-	t.Url = fmt.Sprintf("https://golang.org/dl/go%s.%s.%s", version, arch, ext)
+	return fmt.Sprintf("%s.%s", t.Platform(), ext)
 }
 
-// DownloadGoVersion downloads a specific Go version.
-func (t *Tarball) DownloadGoVersion(version, cachePath string) error {
-	// Get download URL
-	t.GetURL(version)
-
-	// Create HTTP request
-	req, err := http.NewRequest(http.MethodGet, t.Url, nil)
-	if err != nil {
-		return err
-	}
+// GetURL returns the download URL of the archive for the current OS on a mirror.
+func (t *Tarball) GetURL(mirror, version string) {
+	t.Url = fmt.Sprintf("%sgo%s.%s", mirror, version, t.GetArchWithExt())
+}
 
-	// Send HTTP request
-	client := &http.Client{Timeout: 60 * time.Second}
-	resp, err := client.Do(req)
+// DownloadGoVersion downloads a specific Go version and verifies its SHA-256 checksum.
+// The mirrors are tried in order until one of them serves a valid archive.
+// A cached archive is reused when its checksum still matches.
+func (t *Tarball) DownloadGoVersion(version, cachePath string) error {
+	mirrors, err := GetMirrors()
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
 
-	// Check response status
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("no version go%s found. Please use a valid version number", version)
-	}
-
-	// Create a file
 	fileName := fmt.Sprintf("go%s.%s", version, t.GetArchWithExt())
 	file := filepath.Join(cachePath, fileName)
-	f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to create file %s", f)
+	if t.Offline {
+		return t.useCached(version, file)
 	}
-	defer func() {
-		// If there was no previous error, capture any error from closing the file
-		if closeErr := f.Close(); closeErr != nil && err == nil {
-			err = fmt.Errorf("failed to close file %s: %w", file, closeErr)
+
+	notFound := 0
+	for _, mirror := range mirrors {
+		err = t.downloadFromMirror(mirror, version, file)
+		if err == nil {
+			return nil
 		}
-	}()
-
-	// Copy the file to the cache directory
-	if resp.ContentLength > 0 {
-		// Create a progress bar
-		BlackPrintln(fmt.Sprintf("⚡️Downloading go%s", version) + "\n")
-		bar := progressbar.DefaultBytes(resp.ContentLength)
-		if _, err := io.Copy(io.MultiWriter(bar, f), resp.Body); err != nil {
-			return fmt.Errorf("failed to copy %s", file)
+		if isNotFound(err) {
+			notFound++
+		}
+		if len(mirrors) > 1 {
+			t.Downloader.Progress.Println(RedAnsi, fmt.Sprintf("Mirror %s failed: %v", mirror, err)+"\n")
 		}
-		t.File = f
 	}
-	return nil
+	if notFound == len(mirrors) {
+		return fmt.Errorf("no version go%s found. Please use a valid version number", version)
+	}
+	return err
 }
 
-// InstallVersion installs a specific Go version.
-func (t *Tarball) InstallVersion(file, version, versionDir string) error {
-
-	BlackPrintln(fmt.Sprintf("⏳ Installing go%s", version) + "\n")
+// downloadFromMirror downloads the archive of a Go version from a single mirror into file.
+func (t *Tarball) downloadFromMirror(mirror, version, file string) error {
+	t.GetURL(mirror, version)
 
-	versionFolder := filepath.Join(versionDir, fmt.Sprintf("go%s", version))
-	if err := os.MkdirAll(versionFolder, 0755); err != nil {
-		return fmt.Errorf("unable to create version folder %s", versionFolder)
+	// Get the expected checksum when the release index did not provide it
+	if t.Checksum == "" {
+		if err := t.FetchChecksum(); err != nil {
+			return err
+		}
 	}
 
-	switch runtime.GOOS {
-	case "linux", "darwin":
-		cmd := exec.Command("sh", "-c", fmt.Sprintf("tar -xzf %s --strip-components=1 -C %s", file, versionFolder))
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("failed to unzip %s", file)
+	// Reuse the cached archive if it is intact
+	if _, statErr := os.Stat(file); statErr == nil {
+		if verifyErr := VerifyChecksum(file, t.Checksum); verifyErr == nil {
+			t.Downloader.Progress.Println(BlackAnsi, fmt.Sprintf("📦 Using cached go%s", version)+"\n")
+			return t.setFile(file)
 		}
-		return nil
-	case "windows":
-		// For Windows 10 build 17,063 or later which has built-in tar
-		cmd := exec.Command(
-			"powershell", "-Command",
-			fmt.Sprintf("tar -xzf '%s' --strip-components=1 -C '%s'", file, versionFolder),
-		)
-		if err := cmd.Run(); err != nil {
-			// Try to fall back to 7-Zip if available (common on Windows)
-			sevenZipCmd := exec.Command(
-				"powershell", "-Command",
-				fmt.Sprintf(
-					"& { $env:tmp='%s'; 7z x '%s' -o\"$env:tmp\" -y; Get-ChildItem \"$env:tmp\" | Select-Object -First 1 | Get-ChildItem | Move-Item -Destination '%s'; }",
-					os.TempDir(), file, versionFolder,
-				),
-			)
-			if err := sevenZipCmd.Run(); err != nil {
-				return fmt.Errorf("failed to unzip %s (tried both tar and 7z)", file)
-			}
+		t.Downloader.Progress.Println(RedAnsi, fmt.Sprintf("Cached go%s is corrupted, downloading it again", version)+"\n")
+		if err := os.Remove(file); err != nil {
+			return fmt.Errorf("failed to remove corrupted file %s", file)
 		}
-		return nil
 	}
-	return nil
-}
 
-// UseGoVersion sets the current Go version.
-func (t *Tarball) UseGoVersion(version, goVersionDir string) error {
-	goPath := filepath.Join(goVersionDir, fmt.Sprintf("go%s", version), "bin")
-	shellConfig, err := GetShellConfig()
+	// Extract the archive while it downloads when possible
+	streamed, err := t.streamFromMirror(mirror, version, file)
 	if err != nil {
 		return err
 	}
+	if streamed {
+		t.Downloader.Progress.Println(GreenAnsi, fmt.Sprintf("🔒 Checksum verified, go%s served by %s", version, mirror)+"\n")
+		return t.setFile(file)
+	}
 
-	// Set PATH for the current session
-	newPath := fmt.Sprintf("%s%c%s", goPath, os.PathListSeparator, os.Getenv("PATH"))
-	if err := os.Setenv("PATH", newPath); err != nil {
+	// Download the archive, resuming a previously interrupted download
+	t.Downloader.Progress.Println(BlackAnsi, fmt.Sprintf("⚡️Downloading go%s from %s", version, mirror)+"\n")
+	if err := t.Downloader.Download(t.Url, file); err != nil {
 		return err
 	}
 
-	// Persist PATH update in shell profile
-	if err = UpdateShellProfile(goPath); err != nil {
+	// Verify the downloaded archive before it can be extracted
+	if err := VerifyChecksum(file, t.Checksum); err != nil {
+		_ = os.Remove(file)
+		return err
+	}
+	t.Downloaded = true
+	t.Downloader.Progress.Println(GreenAnsi, fmt.Sprintf("🔒 Checksum verified, go%s served by %s", version, mirror)+"\n")
+	return t.setFile(file)
+}
+
+// setFile records the archive file of the tarball.
+func (t *Tarball) setFile(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	t.File = f
+	return f.Close()
+}
+
+// InstallVersion extracts the archive of a Go version into the staging folder
+// of an install, unless it was already extracted while downloading, and commits it.
+func (t *Tarball) InstallVersion(file string, install *Install) error {
+	t.Downloader.Progress.Println(BlackAnsi, fmt.Sprintf("⏳ Installing go%s", install.Version)+"\n")
+
+	if !t.Extracted {
+		if err := ExtractArchive(file, install.StagingDir, t.Downloader.Progress); err != nil {
+			return fmt.Errorf("failed to unzip %s: %v", file, err)
+		}
+	}
+	return install.Commit()
+}
+
+// InstallToolchain installs a Go version from a golang.org/toolchain module zip.
+func (t *Tarball) InstallToolchain(file, platform string, install *Install) error {
+	t.Downloader.Progress.Println(BlackAnsi, fmt.Sprintf("⏳ Installing go%s", install.Version)+"\n")
+
+	if err := ExtractToolchain(file, install.Version, platform, install.StagingDir); err != nil {
+		return fmt.Errorf("failed to unzip %s: %v", file, err)
+	}
+	return install.Commit()
+}
+
+// UseGoVersion sets the current Go version by pointing ~/.govm/current to it.
+// The shell profile puts ~/.govm/current/bin on the PATH once, so the switch
+// applies at once to every shell.
+func (t *Tarball) UseGoVersion(version string) error {
+	dir := Directory{}
+	if err := dir.GetDirectories(); err != nil {
+		return err
+	}
+	if err := dir.SetCurrent(version); err != nil {
 		return err
 	}
 
 	// Print a success message
-	GreenPrintln(
-		"✅ Switched to go" + version + ". " +
-			"Run 'source ~/" + shellConfig + "' or restart your terminal to apply permanently." + "\n",
-	)
+	GreenPrintln("✅ Switched to go" + version + "\n")
+	if t.ShellSession {
+		return nil
+	}
+	return dir.AddToPath()
+}
+
+// PathDirs returns the directories govm puts on the PATH, in order of precedence:
+// the shims once installed, then the bin folder of the active version.
+func (d *Directory) PathDirs() []string {
+	if _, err := os.Stat(d.ShimsDir); err == nil {
+		return []string{d.ShimsDir, d.CurrentBin()}
+	}
+	return []string{d.CurrentBin()}
+}
+
+// AddToPath persists the PATH entry of govm in the shell profile, and tells
+// how to apply it when the current shell does not have it yet.
+func (d *Directory) AddToPath() error {
+	binDirs := d.PathDirs()
+	missing := slices.DeleteFunc(slices.Clone(binDirs), onPath)
 
+	// Windows has no shell profile
+	if runtime.GOOS == "windows" && os.Getenv("SHELL") == "" {
+		if len(missing) > 0 {
+			BlackPrintln("Add " + strings.Join(binDirs, ";") + " in this order to your user PATH environment variable once to use it." + "\n")
+		}
+		return nil
+	}
+	shellConfig, err := GetShellConfig()
+	if err != nil {
+		return err
+	}
+	added, err := EnsureShellProfile(binDirs...)
+	if err != nil {
+		return err
+	}
+	if added {
+		BlackPrintln("Added " + strings.Join(binDirs, " and ") + " to your PATH in ~/" + shellConfig + ". Run 'source ~/" + shellConfig + "' or restart your terminal once to apply it." + "\n")
+	} else if len(missing) > 0 {
+		BlackPrintln("Run 'source ~/" + shellConfig + "' or restart your terminal to put " + strings.Join(missing, " and ") + " on your PATH." + "\n")
+	}
 	return nil
 }
+
+// onPath reports whether dir is on the PATH of the current process.
+func onPath(dir string) bool {
+	for _, entry := range filepath.SplitList(os.Getenv("PATH")) {
+		if filepath.Clean(entry) == dir {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// DefaultMirror serves the Go archives and the release index.
+const DefaultMirror = "https://go.dev/dl/"
+
+// MirrorEnv holds a comma-separated list of mirrors overriding the config file.
+const MirrorEnv = "GOVM_MIRROR"
+
+// GetMirrors returns the mirrors to try in order: GOVM_MIRROR, then the mirrors
+// of the config file, then the default mirror. Each mirror is an http(s) or
+// file:// base URL laid out like https://go.dev/dl/.
+func (c *Config) GetMirrors() ([]string, error) {
+	mirrors := c.Mirrors
+	if env := os.Getenv(MirrorEnv); env != "" {
+		mirrors = strings.Split(env, ",")
+	}
+
+	var bases []string
+	for _, mirror := range mirrors {
+		mirror = strings.TrimSpace(mirror)
+		if mirror == "" {
+			continue
+		}
+		u, err := url.Parse(mirror)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "file") {
+			return nil, fmt.Errorf("invalid mirror %q: expected an http, https or file URL", mirror)
+		}
+		if !strings.HasSuffix(mirror, "/") {
+			mirror += "/"
+		}
+		bases = append(bases, mirror)
+	}
+	if len(bases) == 0 {
+		bases = []string{DefaultMirror}
+	}
+	return bases, nil
+}
+
+// GetMirrors loads the config file and returns the mirrors to try in order.
+func GetMirrors() ([]string, error) {
+	config := Config{}
+	if err := config.Load(); err != nil {
+		return nil, err
+	}
+	return config.GetMirrors()
+}
+
+// indexURL returns the location of the release index on a mirror. A file
+// mirror holds it as index.json since a directory cannot answer queries.
+func indexURL(mirror string) string {
+	if strings.HasPrefix(mirror, "file:") {
+		return mirror + "index.json"
+	}
+	return mirror + "?mode=json&include=all"
+}
+
+// localPath converts a file:// URL to a local path.
+func localPath(fileURL string) (string, error) {
+	u, err := url.Parse(fileURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid file URL %q", fileURL)
+	}
+	path := u.Path
+	if runtime.GOOS == "windows" {
+		path = strings.TrimPrefix(path, "/")
+	}
+	return filepath.FromSlash(path), nil
+}
+
+// openURL opens an http(s) or file URL for reading.
+func openURL(rawURL string) (io.ReadCloser, error) {
+	if strings.HasPrefix(rawURL, "file:") {
+		path, err := localPath(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		f, err := os.Open(path)
+		if os.IsNotExist(err) {
+			return nil, &HTTPStatusError{URL: rawURL, StatusCode: http.StatusNotFound}
+		}
+		return f, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request")
+	}
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, &HTTPStatusError{URL: rawURL, StatusCode: resp.StatusCode}
+	}
+	return resp.Body, nil
+}
+
+// isNotFound reports whether err means the file does not exist on the mirror.
+func isNotFound(err error) bool {
+	var statusErr *HTTPStatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,56 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// missingFromCache describes a file offline mode needs but cannot find in the cache.
+func missingFromCache(what, file string) error {
+	if _, err := os.Stat(file + ".part"); err == nil {
+		return fmt.Errorf("%s is not in the cache, only a partial download %s.part is. Resume it with network access", what, file)
+	}
+	return fmt.Errorf("%s is not in the cache (%s is missing). Download it with network access first", what, file)
+}
+
+// useCached uses the cached archive of a Go version without touching the network.
+func (t *Tarball) useCached(version, file string) error {
+	if _, err := os.Stat(file); err != nil {
+		return fmt.Errorf("%v, or install a local archive with --archive", missingFromCache(fmt.Sprintf("go%s", version), file))
+	}
+
+	if t.Checksum == "" {
+		t.Downloader.Progress.Println(RedAnsi, fmt.Sprintf("No checksum for go%s in the cached release index, using the cached archive as is", version)+"\n")
+	} else if err := VerifyChecksum(file, t.Checksum); err != nil {
+		return fmt.Errorf("cached go%s is corrupted: %v. Download it again with network access", version, err)
+	}
+	t.Downloader.Progress.Println(BlackAnsi, fmt.Sprintf("📦 Using cached go%s (offline)", version)+"\n")
+	return t.setFile(file)
+}
+
+// CachedArchiveReleases returns the releases of the exact versions of specs from
+// their archives in cachePath, to install offline before a release index was cached.
+// The releases carry no checksum, so the archives are installed as they are.
+func CachedArchiveReleases(specs []string, cachePath string) ([]Release, error) {
+	tarball := Tarball{}
+	goos, goarch, _ := strings.Cut(tarball.Platform(), "-")
+	var releases []Release
+	for _, spec := range specs {
+		version, err := ParseGoVersion(NormalizeSpec(spec))
+		if err != nil || isMinorSpec(NormalizeSpec(spec)) {
+			return nil, fmt.Errorf("%s is not an exact version", spec)
+		}
+		name := fmt.Sprintf("go%s.%s", version, tarball.GetArchWithExt())
+		if _, err := os.Stat(filepath.Join(cachePath, name)); err != nil {
+			return nil, missingFromCache("go"+version.String(), filepath.Join(cachePath, name))
+		}
+		releases = append(releases, Release{
+			Version: "go" + version.String(),
+			Stable:  version.Stable(),
+			Files:   []ReleaseFile{{Filename: name, OS: goos, Arch: goarch, Version: "go" + version.String(), Kind: "archive"}},
+		})
+	}
+	return releases, nil
+}
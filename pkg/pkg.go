@@ -28,10 +28,17 @@ func (b *Binary) CachedGoVersion(version string) error {
 		return err
 	}
 
-	t.GetURL(version)
 	fileName := fmt.Sprintf("go%s.%s", version, t.GetArchWithExt())
 	cachedFile := filepath.Join(dir.CacheDir, fileName)
 
+	// Toolchains installed through a module proxy are cached as module zips
+	if _, err := os.Stat(cachedFile); os.IsNotExist(err) {
+		toolchainFile := filepath.Join(dir.CacheDir, fmt.Sprintf("go%s.%s.toolchain.zip", version, HostPlatform()))
+		if _, err := os.Stat(toolchainFile); err == nil {
+			cachedFile = toolchainFile
+		}
+	}
+
 	// Check if the cached file exists
 	if _, err := os.Stat(cachedFile); err == nil {
 		b.CachedGo = cachedFile
@@ -52,11 +59,15 @@ func (b *Binary) GoVersionDetails() error {
 	if err := s.GetActiveGoVersion(); err != nil {
 		return err
 	}
+	dir := Directory{}
+	if err := dir.GetDirectories(); err != nil {
+		return err
+	}
 	sb := strings.Builder{}
 
 	// Print the active Go version
 	if slices.Contains(b.Versions, s.ActiveVersion) {
-		version := TextGreen("→ (Active) - " + s.ActiveVersion + "\n")
+		version := TextGreen("→ (Active) - " + s.ActiveVersion + linkSuffix(dir.ConfigDir, s.ActiveVersion) + "\n")
 		sb.WriteString(version)
 	}
 
@@ -65,13 +76,25 @@ func (b *Binary) GoVersionDetails() error {
 		if name == s.ActiveVersion {
 			continue
 		}
-		sb.WriteString(TextRed("→ (Inactive) - " + name + "\n"))
+		sb.WriteString(TextRed("→ (Inactive) - " + name + linkSuffix(dir.ConfigDir, name) + "\n"))
 	}
 
 	BlackPrintln(sb.String())
 	return nil
 }
 
+// linkSuffix describes where a linked version points to, and nothing for other versions.
+func linkSuffix(versionDir, name string) string {
+	target, ok := LinkTarget(filepath.Join(versionDir, name))
+	if !ok {
+		return ""
+	}
+	if _, err := os.Stat(target); err != nil {
+		return " (linked to " + target + ", missing)"
+	}
+	return " (linked to " + target + ")"
+}
+
 // RemoveGoVersion removes a specific Go version.
 func (b *Binary) RemoveGoVersion(version string) error {
 	// Verify if the Go version is active
@@ -86,12 +109,29 @@ func (b *Binary) RemoveGoVersion(version string) error {
 	}
 
 	// Check if the version exists before proceeding
-	if err := b.CachedGoVersion(version); err != nil {
+	dir := Directory{}
+	if err := dir.GetDirectories(); err != nil {
 		return err
 	}
+	b.InstallDir = dir.ConfigDir
+	if _, err := os.Lstat(filepath.Join(b.InstallDir, goVersion)); err != nil {
+		return fmt.Errorf("go version %s not found", version)
+	}
+
+	// A linked installation is managed elsewhere, only its link is removed
+	target, linked := LinkTarget(filepath.Join(b.InstallDir, goVersion))
+	if !linked {
+		// The archive is not cached anymore when the cache was cleaned
+		_ = b.CachedGoVersion(version)
+	}
+
 	// Ask for confirmation
 	response := ""
-	fmt.Fprintf(os.Stdout, "Do you want to remove go%s? (y/n): ", version)
+	if linked {
+		fmt.Fprintf(os.Stdout, "Do you want to unlink go%s? %s is left in place (y/n): ", version, target)
+	} else {
+		fmt.Fprintf(os.Stdout, "Do you want to remove go%s? (y/n): ", version)
+	}
 	if _, err := fmt.Scanln(&response); err != nil {
 		return err
 	}
@@ -108,6 +148,9 @@ func (b *Binary) RemoveGoVersion(version string) error {
 
 	g.Go(
 		func() error {
+			if b.CachedGo == "" {
+				return nil
+			}
 			return os.Remove(b.CachedGo)
 		},
 	)
@@ -162,10 +205,30 @@ func (b *Binary) GetAllVersions() error {
 		return fmt.Errorf("no versions found. Install a version with 'govm install <version>")
 	}
 	for _, entry := range entries {
-		if entry.IsDir() {
+		// Hidden folders are installs in progress, symbolic links are linked installations
+		if (entry.IsDir() || entry.Type()&os.ModeSymlink != 0) && !strings.HasPrefix(entry.Name(), ".") {
 			versionPath = append(versionPath, entry.Name())
 		}
 	}
+	SortVersionNames(versionPath)
 	b.Versions = versionPath
 	return nil
 }
+
+// SortVersionNames sorts version names such as go1.22.3 from the newest to the oldest.
+// Names that are not Go release versions are sorted alphabetically after them.
+func SortVersionNames(names []string) {
+	slices.SortStableFunc(names, func(a, b string) int {
+		aVersion, aErr := ParseGoVersion(a)
+		bVersion, bErr := ParseGoVersion(b)
+		switch {
+		case aErr == nil && bErr == nil:
+			return bVersion.Compare(aVersion)
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+}
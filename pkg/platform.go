@@ -0,0 +1,106 @@
+package pkg
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// DistPlatforms lists the platforms Go publishes binary distributions for, under
+// the names used in archive file names and in the release index.
+var DistPlatforms = []string{
+	"aix-ppc64",
+	"darwin-amd64", "darwin-arm64",
+	"dragonfly-amd64",
+	"freebsd-386", "freebsd-amd64", "freebsd-arm64", "freebsd-armv6l", "freebsd-riscv64",
+	"illumos-amd64",
+	"linux-386", "linux-amd64", "linux-arm64", "linux-armv6l", "linux-loong64",
+	"linux-mips", "linux-mips64", "linux-mips64le", "linux-mipsle",
+	"linux-ppc64", "linux-ppc64le", "linux-riscv64", "linux-s390x",
+	"netbsd-386", "netbsd-amd64", "netbsd-arm64", "netbsd-armv6l",
+	"openbsd-386", "openbsd-amd64", "openbsd-arm64", "openbsd-armv6l", "openbsd-ppc64", "openbsd-riscv64",
+	"plan9-386", "plan9-amd64", "plan9-arm",
+	"solaris-amd64",
+	"windows-386", "windows-amd64", "windows-arm", "windows-arm64",
+}
+
+// armDistArch is the architecture name of the GOARCH=arm distributions, built for ARMv6
+// so they run on every Raspberry Pi.
+const armDistArch = "armv6l"
+
+// DistPlatform returns the distribution name of a GOOS and GOARCH pair, e.g.
+// linux-armv6l for linux/arm. Distribution names are accepted as is.
+func DistPlatform(goos, goarch string) string {
+	if goarch == "arm" && slices.Contains(DistPlatforms, goos+"-"+armDistArch) {
+		goarch = armDistArch
+	}
+	return goos + "-" + goarch
+}
+
+// ModulePlatform returns the GOOS-GOARCH name of a distribution platform, which
+// golang.org/toolchain module versions use, e.g. linux-arm for linux-armv6l.
+func ModulePlatform(platform string) string {
+	if goos, ok := strings.CutSuffix(platform, "-"+armDistArch); ok {
+		return goos + "-arm"
+	}
+	return platform
+}
+
+// ParsePlatform returns the distribution platform of os-arch or os/arch, where
+// arch is a GOARCH or a distribution name, e.g. linux/arm gives linux-armv6l.
+func ParsePlatform(platform string) (string, error) {
+	goos, goarch, ok := strings.Cut(strings.ReplaceAll(strings.TrimSpace(platform), "/", "-"), "-")
+	if !ok || goos == "" || goarch == "" {
+		return "", fmt.Errorf("invalid platform %q, expected <os>-<arch> such as linux-arm64", platform)
+	}
+	dist := DistPlatform(goos, goarch)
+	if err := ValidatePlatform(dist); err != nil {
+		return "", err
+	}
+	return dist, nil
+}
+
+// UnsupportedPlatformError is returned for a platform nothing is published for.
+type UnsupportedPlatformError struct {
+	What      string
+	Platform  string
+	Supported []string
+}
+
+func (e *UnsupportedPlatformError) Error() string {
+	return fmt.Sprintf("%s is not available for %s. Supported platforms: %s", e.What, e.Platform, strings.Join(e.Supported, ", "))
+}
+
+// ValidatePlatform checks that Go publishes distributions for a platform.
+func ValidatePlatform(platform string) error {
+	if !slices.Contains(DistPlatforms, platform) {
+		return &UnsupportedPlatformError{What: "Go", Platform: platform, Supported: DistPlatforms}
+	}
+	return nil
+}
+
+// Platforms returns the platforms the release publishes an archive for.
+func (r *Release) Platforms() []string {
+	var platforms []string
+	for _, file := range r.Files {
+		if file.Kind == "archive" {
+			platforms = append(platforms, file.OS+"-"+file.Arch)
+		}
+	}
+	slices.Sort(platforms)
+	return slices.Compact(platforms)
+}
+
+// CheckPlatform checks that the release publishes an archive for a platform.
+func (r *Release) CheckPlatform(platform string) error {
+	if r.Archive(platform) == nil {
+		return &UnsupportedPlatformError{What: r.Version, Platform: platform, Supported: r.Platforms()}
+	}
+	return nil
+}
+
+// HostPlatform returns the distribution platform of the running system, e.g. linux-amd64.
+func HostPlatform() string {
+	return DistPlatform(runtime.GOOS, runtime.GOARCH)
+}
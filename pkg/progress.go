@@ -0,0 +1,145 @@
+package pkg
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/schollz/progressbar/v3"
+	"golang.org/x/term"
+)
+
+// Progress is a multi-line display showing one line per concurrent operation,
+// each with its latest message and progress bar. When the output is not a
+// terminal, the messages are printed as they come, prefixed with the label of
+// their line, and the progress bars are left out.
+type Progress struct {
+	mu    sync.Mutex
+	out   io.Writer
+	tty   bool
+	lines []*ProgressLine
+	drawn int
+}
+
+// NewProgress returns a multi-line display printing to the console.
+func NewProgress() *Progress {
+	return &Progress{out: os.Stdout, tty: term.IsTerminal(int(os.Stdout.Fd()))}
+}
+
+// Line adds a line to the display.
+func (p *Progress) Line(label string) *ProgressLine {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	line := &ProgressLine{progress: p, label: label}
+	p.lines = append(p.lines, line)
+	p.render()
+	return line
+}
+
+// render redraws all the lines in place. It must be called with the lock held.
+func (p *Progress) render() {
+	if !p.tty {
+		return
+	}
+	var sb strings.Builder
+	if p.drawn > 0 {
+		sb.WriteString(fmt.Sprintf("\033[%dA", p.drawn))
+	}
+	width := 0
+	for _, line := range p.lines {
+		width = max(width, len(line.label))
+	}
+	columns, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		columns = 80
+	}
+	for _, line := range p.lines {
+		// Lines must not wrap, the cursor moves up one row per line
+		message := []rune(line.message)
+		bar := []rune(line.bar)
+		if len(bar) > 0 {
+			bar = append([]rune("  "), bar...)
+		}
+		if room := columns - width - 4 - len(bar); len(message) > room {
+			message = message[:max(room, 0)]
+		}
+		sb.WriteString("\r\033[2K")
+		sb.WriteString(fmt.Sprintf("%-*s  %s%s%s%s", width, line.label, line.color, string(message), ResetAnsi, string(bar)))
+		sb.WriteString("\n")
+	}
+	p.drawn = len(p.lines)
+	_, _ = fmt.Fprint(p.out, sb.String())
+}
+
+// ProgressLine is a line of a multi-line display. A nil line prints to the
+// console like BlackPrintln and shows the default progress bars.
+type ProgressLine struct {
+	progress *Progress
+	label    string
+	color    string
+	message  string
+	bar      string
+}
+
+// Println shows a message in the given ANSI color.
+func (l *ProgressLine) Println(color, text string) {
+	if l == nil {
+		_, _ = fmt.Fprint(os.Stdout, color+text+ResetAnsi)
+		return
+	}
+
+	l.progress.mu.Lock()
+	defer l.progress.mu.Unlock()
+	l.color = color
+	l.message = strings.Join(strings.Fields(text), " ")
+	l.bar = ""
+	if !l.progress.tty {
+		_, _ = fmt.Fprintf(l.progress.out, "%s: %s%s%s\n", l.label, color, l.message, ResetAnsi)
+		return
+	}
+	l.progress.render()
+}
+
+// NewBar returns a progress bar of max bytes, drawn on the line.
+// A negative max draws a spinner.
+func (l *ProgressLine) NewBar(max int64, description ...string) *progressbar.ProgressBar {
+	if l == nil {
+		return progressbar.DefaultBytes(max, description...)
+	}
+	desc := ""
+	if len(description) > 0 {
+		desc = description[0]
+	}
+	return progressbar.NewOptions64(max,
+		progressbar.OptionSetDescription(desc),
+		progressbar.OptionSetWriter(barWriter{l}),
+		progressbar.OptionShowBytes(true),
+		progressbar.OptionSetWidth(20),
+		progressbar.OptionThrottle(100*time.Millisecond),
+		progressbar.OptionShowCount(),
+		progressbar.OptionSpinnerType(14),
+	)
+}
+
+// barWriter receives the renders of a progress bar and shows the latest one on its line.
+type barWriter struct {
+	line *ProgressLine
+}
+
+func (w barWriter) Write(b []byte) (int, error) {
+	l := w.line
+	frames := strings.Split(string(b), "\r")
+	frame := strings.TrimSpace(frames[len(frames)-1])
+	if frame == "" {
+		return len(b), nil
+	}
+
+	l.progress.mu.Lock()
+	defer l.progress.mu.Unlock()
+	l.bar = frame
+	l.progress.render()
+	return len(b), nil
+}
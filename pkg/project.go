@@ -0,0 +1,163 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// FindGoDirectives walks up from dir to the nearest go.work file, or else the
+// nearest go.mod file, and selects the Go version its directives ask for: the
+// toolchain directive names an exact version, the go directive a minimum one.
+func FindGoDirectives(dir string) (VersionSelection, bool, error) {
+	for _, name := range []string{"go.work", "go.mod"} {
+		file, found := findUp(dir, name)
+		if !found {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return VersionSelection{}, false, fmt.Errorf("failed to read %s: %v", file, err)
+		}
+
+		var goVersion, toolchain string
+		if name == "go.work" {
+			f, err := modfile.ParseWork(file, data, nil)
+			if err != nil {
+				return VersionSelection{}, false, err
+			}
+			if f.Go != nil {
+				goVersion = f.Go.Version
+			}
+			if f.Toolchain != nil {
+				toolchain = f.Toolchain.Name
+			}
+		} else {
+			// Lax parsing skips the toolchain directive, it is only used when strict parsing fails
+			f, err := modfile.Parse(file, data, nil)
+			if err != nil {
+				if f, err = modfile.ParseLax(file, data, nil); err != nil {
+					return VersionSelection{}, false, err
+				}
+			}
+			if f.Go != nil {
+				goVersion = f.Go.Version
+			}
+			if f.Toolchain != nil {
+				toolchain = f.Toolchain.Name
+			}
+		}
+		return directiveSelection(file, goVersion, toolchain)
+	}
+	return VersionSelection{}, false, nil
+}
+
+// directiveSelection selects the Go version asked for by the go and toolchain directives of file.
+// A toolchain older than the go directive is ignored, like the go command does.
+func directiveSelection(file, goVersion, toolchain string) (VersionSelection, bool, error) {
+	if version, err := ParseGoVersion(strings.TrimPrefix(toolchain, "go")); err == nil {
+		minimum, err := ParseGoVersion(goVersion)
+		if err != nil || version.Compare(minimum) >= 0 {
+			return VersionSelection{Spec: version.String(), Source: file}, true, nil
+		}
+	}
+	if goVersion == "" {
+		return VersionSelection{}, false, nil
+	}
+	if _, err := ParseGoVersion(goVersion); err != nil {
+		return VersionSelection{}, false, fmt.Errorf("unexpected go directive %q in %s", goVersion, file)
+	}
+	return VersionSelection{Spec: goVersion, Source: file, Minimum: true}, true, nil
+}
+
+// findUp walks up from dir to the nearest directory holding name.
+func findUp(dir, name string) (string, bool) {
+	for {
+		file := filepath.Join(dir, name)
+		if info, err := os.Stat(file); err == nil && !info.IsDir() {
+			return file, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// SelectProjectVersion returns the Go version the project holding dir selects,
+// with a .go-version or .tool-versions file, or else with the go and toolchain
+// directives of its go.work or go.mod file.
+func SelectProjectVersion(dir string) (VersionSelection, bool, error) {
+	selection, found, err := FindVersionFile(dir)
+	if err != nil || found {
+		return selection, found, err
+	}
+	return FindGoDirectives(dir)
+}
+
+// installingEnv is set for the govm install run by InstallSelection. A shim run
+// meanwhile, e.g. by make.bash, does not start another install.
+const installingEnv = "GOVM_INSTALLING"
+
+// govmExecutable returns the govm executable. A Windows shim is a copy of govm that
+// runs as the Go command, govm is then looked up on the PATH.
+func govmExecutable() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("unable to locate the govm executable: %v", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	if ShimName(exe) == "" {
+		return exe, nil
+	}
+	if path, err := exec.LookPath("govm"); err == nil && ShimName(path) == "" {
+		return path, nil
+	}
+	return "", fmt.Errorf("unable to locate the govm executable, %s is a shim and govm is not on the PATH", exe)
+}
+
+// InstallSelection installs the Go version of a selection by running govm install,
+// printing its progress on stderr so the output of the command being run stays clean.
+// A minimum version installs the newest release of its minor line.
+func InstallSelection(selection VersionSelection) error {
+	spec := NormalizeSpec(selection.Spec)
+	if selection.Minimum {
+		if version, err := ParseGoVersion(spec); err == nil {
+			spec = version.MinorLine()
+		}
+	}
+	exe, err := govmExecutable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(exe, "install", spec, "--use", "none")
+	cmd.Env = append(os.Environ(), installingEnv+"=1")
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to install go%s selected by %s", spec, selection.Source)
+	}
+	return nil
+}
+
+// ResolveOrInstall returns the installed Go version matching a selection,
+// installing it first when it is missing and install is set, unless an install
+// started by InstallSelection is already running.
+func ResolveOrInstall(selection VersionSelection, install bool) (string, error) {
+	version, err := ResolveSelection(selection)
+	if err == nil || !install || os.Getenv(installingEnv) != "" {
+		return version, err
+	}
+	if err := InstallSelection(selection); err != nil {
+		return "", err
+	}
+	return ResolveSelection(selection)
+}
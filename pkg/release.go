@@ -0,0 +1,166 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ReleaseFile describes a single downloadable file of a Go release.
+type ReleaseFile struct {
+	Filename string `json:"filename"`
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	Version  string `json:"version"`
+	SHA256   string `json:"sha256"`
+	Size     int64  `json:"size"`
+	Kind     string `json:"kind"`
+}
+
+// Release describes a Go release as published in the go.dev release index.
+type Release struct {
+	Version   string        `json:"version"`
+	Stable    bool          `json:"stable"`
+	Files     []ReleaseFile `json:"files"`
+	Installed bool          `json:"-"`
+}
+
+// ErrNoCachedIndex is returned offline when no release index was cached yet.
+var ErrNoCachedIndex = errors.New("no cached release index")
+
+type ReleaseIndex struct {
+	Releases []Release
+}
+
+// Fetch downloads and parses the release index, trying the mirrors in order.
+func (r *ReleaseIndex) Fetch() error {
+	mirrors, err := GetMirrors()
+	if err != nil {
+		return err
+	}
+
+	for _, mirror := range mirrors {
+		var releases []Release
+		releases, err = fetchReleases(indexURL(mirror))
+		if err == nil {
+			r.Releases = releases
+			r.save()
+			return nil
+		}
+	}
+	return fmt.Errorf("failed to fetch the Go release index: %v. Use --offline to work from the cache", err)
+}
+
+// Load fetches the release index, or reads the cached one when offline.
+func (r *ReleaseIndex) Load(offline bool) error {
+	if offline {
+		return r.LoadCached()
+	}
+	return r.Fetch()
+}
+
+// cachedIndexFile returns where the last fetched release index is kept for offline use.
+func cachedIndexFile() (string, error) {
+	dir := Directory{}
+	if err := dir.GetDirectories(); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir.CacheDir, "releases.json"), nil
+}
+
+// save keeps a copy of the release index in the cache. Failing to save it only
+// means offline mode keeps using an older copy.
+func (r *ReleaseIndex) save() {
+	file, err := cachedIndexFile()
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(r.Releases)
+	if err != nil {
+		return
+	}
+	_ = writeFileAtomic(file, data)
+}
+
+// LoadCached reads the release index saved by the last successful Fetch.
+func (r *ReleaseIndex) LoadCached() error {
+	file, err := cachedIndexFile()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%w (%s is missing). Run 'govm ls-remote' once with network access", ErrNoCachedIndex, file)
+	} else if err != nil {
+		return fmt.Errorf("failed to read cached release index %s: %v", file, err)
+	}
+	if err := json.Unmarshal(data, &r.Releases); err != nil {
+		return fmt.Errorf("invalid cached release index %s: %v", file, err)
+	}
+	return nil
+}
+
+// fetchReleases downloads and decodes a release index.
+func fetchReleases(indexURL string) ([]Release, error) {
+	body, err := openURL(indexURL)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = body.Close()
+	}()
+
+	var releases []Release
+	if err := json.NewDecoder(body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("failed to decode %s", indexURL)
+	}
+	return releases, nil
+}
+
+// MarkInstalled flags the releases that are installed in versionDir.
+func (r *ReleaseIndex) MarkInstalled(versionDir string) {
+	for i := range r.Releases {
+		info, err := os.Stat(filepath.Join(versionDir, r.Releases[i].Version))
+		r.Releases[i].Installed = err == nil && info.IsDir()
+	}
+}
+
+// Filter returns the releases matching the given criteria.
+// An empty minor or platform disables the corresponding filter.
+func (r *ReleaseIndex) Filter(stableOnly bool, minor, platform string) []Release {
+	var releases []Release
+	for _, release := range r.Releases {
+		if stableOnly && !release.Stable {
+			continue
+		}
+		if minor != "" && !release.InMinor(minor) {
+			continue
+		}
+		if platform != "" && release.Archive(platform) == nil {
+			continue
+		}
+		releases = append(releases, release)
+	}
+	return releases
+}
+
+// InMinor reports whether the release belongs to the given minor line, e.g. 1.22.
+func (r *Release) InMinor(minor string) bool {
+	version, err := ParseGoVersion(r.Version)
+	if err != nil {
+		return false
+	}
+	return version.MinorLine() == NormalizeSpec(minor)
+}
+
+// Archive returns the archive file of the release for a platform such as linux-arm64.
+func (r *Release) Archive(platform string) *ReleaseFile {
+	for i, file := range r.Files {
+		if file.Kind == "archive" && file.OS+"-"+file.Arch == platform {
+			return &r.Files[i]
+		}
+	}
+	return nil
+}
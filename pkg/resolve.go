@@ -0,0 +1,186 @@
+package pkg
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Symbolic version specs understood by the resolver.
+const (
+	SpecLatest    = "latest"    // newest release, including release candidates and betas
+	SpecStable    = "stable"    // newest stable release
+	SpecOldStable = "oldstable" // newest stable release of the previous minor line
+)
+
+// NormalizeSpec trims a version spec and removes its optional "go" prefix.
+func NormalizeSpec(spec string) string {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	return strings.TrimPrefix(spec, "go")
+}
+
+// isMinorSpec reports whether spec is a bare minor version such as 1.22.
+func isMinorSpec(spec string) bool {
+	parts := strings.Split(spec, ".")
+	if len(parts) != 2 {
+		return false
+	}
+	for _, part := range parts {
+		if _, err := strconv.Atoi(part); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveSpec picks the version matching spec from versions, which must be sorted newest first.
+// The stable function reports whether a version is a stable release.
+func resolveSpec(spec string, versions []GoVersion, stable func(GoVersion) bool) (GoVersion, error) {
+	spec = NormalizeSpec(spec)
+	if spec == "" {
+		return GoVersion{}, fmt.Errorf("invalid version format. Please enter a valid version")
+	}
+
+	switch spec {
+	case SpecLatest:
+		if len(versions) > 0 {
+			return versions[0], nil
+		}
+	case SpecStable, SpecOldStable:
+		newest := ""
+		for _, version := range versions {
+			if !stable(version) {
+				continue
+			}
+			if spec == SpecStable {
+				return version, nil
+			}
+			if newest == "" {
+				newest = version.MinorLine()
+			} else if version.MinorLine() != newest {
+				return version, nil
+			}
+		}
+	default:
+		if isMinorSpec(spec) {
+			// Prefer the newest stable patch, fall back to a pre-release of that minor
+			var candidate *GoVersion
+			for _, version := range versions {
+				if version.MinorLine() != spec {
+					continue
+				}
+				if stable(version) {
+					return version, nil
+				}
+				if candidate == nil {
+					candidate = &version
+				}
+			}
+			if candidate != nil {
+				return *candidate, nil
+			}
+			break
+		}
+		exact, err := ParseGoVersion(spec)
+		if err != nil {
+			return GoVersion{}, fmt.Errorf("invalid version format. Please enter a valid version")
+		}
+		for _, version := range versions {
+			if version.Compare(exact) == 0 {
+				return version, nil
+			}
+		}
+	}
+	return GoVersion{}, fmt.Errorf("no version matching %q found", spec)
+}
+
+// Resolve turns a version spec such as latest, stable, oldstable, 1.22 or go1.22.3
+// into a concrete release of the index.
+func (r *ReleaseIndex) Resolve(spec string) (Release, error) {
+	releases := map[GoVersion]Release{}
+	versions := make([]GoVersion, 0, len(r.Releases))
+	for _, release := range r.Releases {
+		version, err := ParseGoVersion(release.Version)
+		if err != nil {
+			continue
+		}
+		releases[version] = release
+		versions = append(versions, version)
+	}
+	sortNewestFirst(versions)
+
+	version, err := resolveSpec(spec, versions, func(v GoVersion) bool { return releases[v].Stable })
+	if err != nil {
+		return Release{}, fmt.Errorf("%v. Run 'govm ls-remote' to list available versions", err)
+	}
+	return releases[version], nil
+}
+
+// ResolveVersion turns a version spec into one of the installed versions
+// loaded by GetAllVersions. The returned version has no "go" prefix.
+// Source builds match by their exact name, tip by the most recent tip build.
+func (b *Binary) ResolveVersion(spec string) (string, error) {
+	if name := NormalizeSpec(spec); slices.Contains(b.Versions, "go"+name) {
+		return name, nil
+	} else if name == SpecTip {
+		dir := Directory{}
+		if err := dir.GetDirectories(); err != nil {
+			return "", err
+		}
+		if version, ok := NewestSourceBuild(dir.ConfigDir, b.Versions, SpecTip+"-"); ok {
+			return version, nil
+		}
+		return "", fmt.Errorf("no tip build installed. Build one with 'govm install tip'")
+	}
+
+	versions := make([]GoVersion, 0, len(b.Versions))
+	for _, name := range b.Versions {
+		if version, err := ParseGoVersion(name); err == nil {
+			versions = append(versions, version)
+		}
+	}
+	sortNewestFirst(versions)
+
+	version, err := resolveSpec(spec, versions, GoVersion.Stable)
+	if err != nil {
+		return "", fmt.Errorf("%v. Run 'govm list' to list installed versions", err)
+	}
+	return version.String(), nil
+}
+
+// ResolveMinimum returns the newest installed release not older than the
+// minimum version of a selection, preferring stable releases.
+func (b *Binary) ResolveMinimum(selection VersionSelection) (string, error) {
+	minimum, err := ParseGoVersion(selection.Spec)
+	if err != nil {
+		return "", err
+	}
+	var candidate string
+	for _, name := range b.Versions {
+		version, err := ParseGoVersion(name)
+		if err != nil || version.Compare(minimum) < 0 {
+			continue
+		}
+		if version.Stable() {
+			return version.String(), nil
+		}
+		if candidate == "" {
+			candidate = version.String()
+		}
+	}
+	if candidate == "" {
+		return "", fmt.Errorf(
+			"%s selected by %s is not installed. Run 'govm install %s'",
+			selection, selection.Source, minimum.MinorLine(),
+		)
+	}
+	return candidate, nil
+}
+
+// sortNewestFirst sorts versions from the newest to the oldest.
+func sortNewestFirst(versions []GoVersion) {
+	slices.SortFunc(versions, func(a, b GoVersion) int {
+		return b.Compare(a)
+	})
+}
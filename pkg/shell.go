@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
 	"runtime"
+	"slices"
 	"strings"
 )
 
@@ -15,30 +18,77 @@ type ShellConfig struct {
 	ActiveVersion string
 }
 
-// UpdateShellProfile updates the shell profile.
-func UpdateShellProfile(goPath string) error {
+// profileMarker ends the line govm adds to the shell profile.
+const profileMarker = "# added by govm"
+
+// oldGoPathLine matches the PATH entries of a single Go version written by earlier versions of govm.
+var oldGoPathLine = regexp.MustCompile(`^export PATH=.*\.govm/versions/go/`)
+
+// EnsureShellProfile puts binDirs on the PATH in the shell profile with a single
+// line, the first directory taking precedence. It reports whether the profile was
+// changed, after which new shells pick up the entry.
+func EnsureShellProfile(binDirs ...string) (bool, error) {
 	// Get shell config
 	shellConfig, err := GetShellConfig()
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	// Check if shell config is valid
 	if shellConfig == "" {
-		return fmt.Errorf("invalid shell configuration file name")
+		return false, fmt.Errorf("invalid shell configuration file name")
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return false, fmt.Errorf("unable to get home directory")
 	}
+	profile := filepath.Join(homeDir, shellConfig)
 
-	// Remove old Go path entries
-	if err := RemoveOldGoPaths(shellConfig); err != nil {
-		return err
+	// Build the PATH entry, relative to the home directory so the profile can be shared
+	entries := make([]string, 0, len(binDirs))
+	for _, binDir := range binDirs {
+		if rel, err := filepath.Rel(homeDir, binDir); err == nil && !strings.HasPrefix(rel, "..") {
+			binDir = "$HOME/" + filepath.ToSlash(rel)
+		}
+		entries = append(entries, binDir)
+	}
+	line := fmt.Sprintf("export PATH=\"%s:$PATH\" %s", strings.Join(entries, ":"), profileMarker)
+	if strings.HasSuffix(shellConfig, ".fish") {
+		line = fmt.Sprintf("set -gx PATH \"%s\" $PATH %s", strings.Join(entries, "\" \""), profileMarker)
 	}
 
-	// Append the new Go path
-	cmd := exec.Command("sh", "-c", fmt.Sprintf("echo 'export PATH=\"%s:$PATH\"' >> ~/%s", goPath, shellConfig))
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to append new Go path to %s: %s", shellConfig, err.Error())
+	// Remove the entries of single Go versions and the previous entry of govm
+	if err := removeProfileLines(shellConfig, func(l string) bool {
+		return oldGoPathLine.MatchString(l) || (strings.Contains(l, profileMarker) && strings.TrimSpace(l) != line)
+	}); err != nil {
+		return false, err
 	}
-	return nil
+	data, err := os.ReadFile(profile)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to read %s: %v", profile, err)
+	}
+	if strings.Contains(string(data), line) {
+		return false, nil
+	}
+
+	// Append the PATH entry
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		line = "\n" + line
+	}
+	if err := os.MkdirAll(filepath.Dir(profile), 0755); err != nil {
+		return false, fmt.Errorf("failed to create %s", filepath.Dir(profile))
+	}
+	f, err := os.OpenFile(profile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return false, fmt.Errorf("failed to open %s: %v", profile, err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		return false, fmt.Errorf("failed to append the Go path to %s: %v", shellConfig, err)
+	}
+	return true, nil
 }
 
 // GetShellConfig returns the shell config file.
@@ -66,25 +116,64 @@ func GetShellConfig() (string, error) {
 	return "", fmt.Errorf("unsupported shell: %s", shell)
 }
 
-// RemoveOldGoPaths removes old Go paths from the shell profile.
-func RemoveOldGoPaths(shellConfig string) error {
+// removeProfileLines removes the lines matching drop from the shell profile.
+// A missing profile has nothing to remove.
+func removeProfileLines(shellConfig string, drop func(line string) bool) error {
 	// Check if shell config is valid
 	if shellConfig == "" {
 		return fmt.Errorf("invalid shell configuration file name")
 	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("unable to get home directory")
+	}
+	profile := filepath.Join(homeDir, shellConfig)
 
-	// Portable `sed` command for macOS and Linux
-	cmdStr := fmt.Sprintf("sed -i '' '/export PATH=.*.govm\\/version/d' ~/%s", shellConfig)
-	cmd := exec.Command("sh", "-c", cmdStr)
+	data, err := os.ReadFile(profile)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read %s: %v", profile, err)
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	kept := slices.DeleteFunc(slices.Clone(lines), func(line string) bool {
+		return drop(strings.TrimRight(line, "\r\n"))
+	})
+	if len(kept) == len(lines) {
+		return nil
+	}
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to remove old Go paths from %s", shellConfig)
+	// Write the profile next to the original and swap them, keeping its permissions
+	if resolved, err := filepath.EvalSymlinks(profile); err == nil {
+		profile = resolved
+	}
+	info, err := os.Stat(profile)
+	if err != nil {
+		return err
+	}
+	tmp := profile + ".govm"
+	if err := os.WriteFile(tmp, []byte(strings.Join(kept, "")), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to remove old Go paths from %s: %v", shellConfig, err)
+	}
+	if err := os.Rename(tmp, profile); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to remove old Go paths from %s: %v", shellConfig, err)
 	}
 	return nil
 }
 
 // GetActiveGoVersion returns the active Go version.
 func (s *ShellConfig) GetActiveGoVersion() error {
+	// The active version is the one ~/.govm/current points to
+	dir := Directory{}
+	if err := dir.GetDirectories(); err != nil {
+		return err
+	}
+	if current, err := dir.Current(); err == nil {
+		s.ActiveVersion = current
+		return nil
+	}
+
 	// Check if go is installed
 	if _, err := exec.LookPath("go"); err != nil {
 		return fmt.Errorf("go is not installed. Please install go first with 'govm install <version>'")
@@ -98,5 +187,32 @@ func (s *ShellConfig) GetActiveGoVersion() error {
 	}
 	versions := strings.Split(string(output), " ")
 	s.ActiveVersion = versions[2]
+
+	// Name the version after its folder, so source builds and linked installations are recognized
+	goRoot, err := exec.Command("go", "env", "GOROOT").Output()
+	if err != nil {
+		return fmt.Errorf("could not determine active Go version")
+	}
+	if name, ok := installedName(strings.TrimSpace(string(goRoot))); ok {
+		s.ActiveVersion = name
+	}
 	return nil
 }
+
+// installedName returns the name of the installed version whose folder is goRoot.
+func installedName(goRoot string) (string, bool) {
+	dir := Directory{}
+	if err := dir.GetDirectories(); err != nil {
+		return "", false
+	}
+	entries, err := os.ReadDir(dir.ConfigDir)
+	if err != nil {
+		return "", false
+	}
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), ".") && sameFile(filepath.Join(dir.ConfigDir, entry.Name()), goRoot) {
+			return entry.Name(), true
+		}
+	}
+	return "", false
+}
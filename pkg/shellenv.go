@@ -0,0 +1,117 @@
+package pkg
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+//go:embed shells
+var shellScripts embed.FS
+
+// Shells lists the shells govm integrates with.
+var Shells = []string{"bash", "zsh", "fish", "pwsh"}
+
+// ValidateShell checks that govm integrates with a shell.
+func ValidateShell(shell string) error {
+	if !slices.Contains(Shells, shell) {
+		return fmt.Errorf("unsupported shell %q. Supported shells: %s", shell, strings.Join(Shells, ", "))
+	}
+	return nil
+}
+
+// EnvVar is an environment variable to set in a shell, or to unset when Value is empty.
+type EnvVar struct {
+	Name  string
+	Value string
+}
+
+// FormatEnv returns the statements setting vars in a shell.
+func FormatEnv(shell string, vars []EnvVar) string {
+	var sb strings.Builder
+	for _, v := range vars {
+		switch {
+		case shell == "fish" && v.Value == "":
+			sb.WriteString(fmt.Sprintf("set -e %s\n", v.Name))
+		case shell == "fish" && v.Name == "PATH":
+			// PATH is a list in fish
+			sb.WriteString("set -gx PATH")
+			for _, dir := range filepath.SplitList(v.Value) {
+				sb.WriteString(" " + quoteFish(dir))
+			}
+			sb.WriteString("\n")
+		case shell == "fish":
+			sb.WriteString(fmt.Sprintf("set -gx %s %s\n", v.Name, quoteFish(v.Value)))
+		case shell == "pwsh" && v.Value == "":
+			sb.WriteString(fmt.Sprintf("Remove-Item Env:%s -ErrorAction SilentlyContinue\n", v.Name))
+		case shell == "pwsh":
+			sb.WriteString(fmt.Sprintf("$env:%s = '%s'\n", v.Name, strings.ReplaceAll(v.Value, "'", "''")))
+		case v.Value == "":
+			sb.WriteString(fmt.Sprintf("unset %s\n", v.Name))
+		default:
+			sb.WriteString(fmt.Sprintf("export %s='%s'\n", v.Name, strings.ReplaceAll(v.Value, "'", `'\''`)))
+		}
+	}
+
+	// bash remembers where it found commands
+	if shell == "bash" && len(vars) > 0 {
+		sb.WriteString("hash -r\n")
+	}
+	return sb.String()
+}
+
+// quoteFish quotes a value for fish.
+func quoteFish(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(value) + "'"
+}
+
+// SessionEnv returns the environment changes putting govm's directories first on
+// pathList, in place of the bin folders of single Go versions, and dropping a
+// GOROOT pointing to one of the installed versions.
+func (d *Directory) SessionEnv(pathList string) []EnvVar {
+	dirs := d.PathDirs()
+	for _, entry := range filepath.SplitList(pathList) {
+		if entry == "" || slices.Contains(dirs, filepath.Clean(entry)) || d.isVersionDir(entry) {
+			continue
+		}
+		dirs = append(dirs, entry)
+	}
+	vars := []EnvVar{{Name: "PATH", Value: strings.Join(dirs, string(os.PathListSeparator))}}
+	if goRoot := os.Getenv("GOROOT"); goRoot != "" && d.isVersionDir(goRoot) {
+		vars = append(vars, EnvVar{Name: "GOROOT"})
+	}
+
+	// The directory hook switches to the project version again on the next cd,
+	// the GOROOT it replaced is restored meanwhile
+	if state := os.Getenv(hookStateVar); state != "" {
+		if _, _, goRoot, _ := parseHookState(state); goRoot != "" && !d.isVersionDir(goRoot) {
+			vars = append(vars, EnvVar{Name: "GOROOT", Value: goRoot})
+		}
+		vars = append(vars, EnvVar{Name: hookStateVar})
+	}
+	return vars
+}
+
+// isVersionDir reports whether path is inside the folder of an installed Go version.
+func (d *Directory) isVersionDir(path string) bool {
+	rel, err := filepath.Rel(d.ConfigDir, path)
+	return err == nil && rel != "." && !strings.HasPrefix(rel, "..")
+}
+
+// ShellInit returns the script integrating govm with a shell: it puts govm's
+// directories on the PATH and defines a govm function applying `govm use`
+// to the shell at once.
+func (d *Directory) ShellInit(shell string) (string, error) {
+	if err := ValidateShell(shell); err != nil {
+		return "", err
+	}
+	name := map[string]string{"bash": "govm.sh", "zsh": "govm.sh", "fish": "govm.fish", "pwsh": "govm.ps1"}[shell]
+	script, err := shellScripts.ReadFile("shells/" + name)
+	if err != nil {
+		return "", err
+	}
+	return FormatEnv(shell, d.SessionEnv(os.Getenv("PATH"))) + strings.ReplaceAll(string(script), "__SHELL__", shell), nil
+}
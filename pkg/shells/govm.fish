@@ -0,0 +1,9 @@
+# govm shell integration: `govm use` switches the Go version of this shell at once.
+function govm
+    if test "$argv[1]" = use
+        set -l govm_env (command govm use --env fish $argv[2..-1]); or return
+        string join \n $govm_env | source
+    else
+        command govm $argv
+    end
+end
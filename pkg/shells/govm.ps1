@@ -0,0 +1,13 @@
+# govm shell integration: `govm use` switches the Go version of this shell at once.
+function govm {
+    $govm = Get-Command govm -CommandType Application | Select-Object -First 1
+    if ($args.Count -gt 0 -and $args[0] -eq 'use') {
+        $rest = @($args | Select-Object -Skip 1)
+        $govmEnv = & $govm use --env pwsh @rest
+        if ($LASTEXITCODE -eq 0) {
+            $govmEnv | Out-String | Invoke-Expression
+        }
+    } else {
+        & $govm @args
+    }
+}
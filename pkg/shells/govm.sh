@@ -0,0 +1,11 @@
+# govm shell integration: `govm use` switches the Go version of this shell at once.
+govm() {
+  if [ "$1" = "use" ]; then
+    shift
+    local govm_env
+    govm_env="$(command govm use --env __SHELL__ "$@")" || return
+    eval "$govm_env"
+  else
+    command govm "$@"
+  fi
+}
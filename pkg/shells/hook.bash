@@ -0,0 +1,10 @@
+# govm hook: switches the Go version of this shell when entering a project.
+__govm_hook() {
+  [ "$PWD" = "${__GOVM_PWD-}" ] && return
+  __GOVM_PWD="$PWD"
+  eval "$(command govm hook bash --env)"
+}
+case ";${PROMPT_COMMAND-};" in
+  *";__govm_hook;"*) ;;
+  *) PROMPT_COMMAND="__govm_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}" ;;
+esac
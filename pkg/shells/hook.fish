@@ -0,0 +1,5 @@
+# govm hook: switches the Go version of this shell when entering a project.
+function __govm_hook --on-variable PWD
+    command govm hook fish --env | source
+end
+__govm_hook
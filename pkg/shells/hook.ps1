@@ -0,0 +1,12 @@
+# govm hook: switches the Go version of this shell when entering a project.
+if (-not $global:GovmPrompt) {
+    $global:GovmPrompt = $function:prompt
+}
+function global:prompt {
+    if ($PWD.Path -ne $global:GovmPwd) {
+        $global:GovmPwd = $PWD.Path
+        $govm = Get-Command govm -CommandType Application | Select-Object -First 1
+        & $govm hook pwsh --env | Out-String | Invoke-Expression
+    }
+    & $global:GovmPrompt
+}
@@ -0,0 +1,223 @@
+package pkg
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// ShimNames are the Go commands govm installs shims for.
+var ShimNames = []string{"go", "gofmt"}
+
+// Version files selecting the Go version of a directory and its sub directories.
+const (
+	GoVersionFile   = ".go-version"
+	ToolVersionFile = ".tool-versions"
+)
+
+// ShimName returns the Go command govm runs as when invoked through a shim,
+// e.g. go for ~/.govm/shims/go, or an empty string for govm itself.
+func ShimName(arg0 string) string {
+	name := strings.TrimSuffix(filepath.Base(arg0), ".exe")
+	if slices.Contains(ShimNames, name) {
+		return name
+	}
+	return ""
+}
+
+// InstallShims links the shims in ~/.govm/shims to the running govm executable.
+// Windows shims are copies of it, since symbolic links need extra privileges there.
+func (d *Directory) InstallShims() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("unable to locate the govm executable: %v", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	if err := os.MkdirAll(d.ShimsDir, 0755); err != nil {
+		return fmt.Errorf("unable to create shims directory %s", d.ShimsDir)
+	}
+
+	for _, name := range ShimNames {
+		shim := filepath.Join(d.ShimsDir, name)
+		if runtime.GOOS == "windows" {
+			shim += ".exe"
+		} else if target, err := os.Readlink(shim); err == nil && target == exe {
+			continue
+		}
+
+		// Replace the shim in one step, a shell may be running it
+		tmp := shim + ".tmp"
+		_ = os.Remove(tmp)
+		if runtime.GOOS == "windows" {
+			err = copyFile(exe, tmp)
+		} else {
+			err = os.Symlink(exe, tmp)
+		}
+		if err == nil {
+			err = os.Rename(tmp, shim)
+		}
+		if err != nil {
+			_ = os.Remove(tmp)
+			return fmt.Errorf("failed to install the %s shim: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// copyFile copies the executable src to dest.
+func copyFile(src, dest string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	return writeFile(dest, f, 0755)
+}
+
+// VersionSelection is the Go version selected for a directory, with what selected it.
+type VersionSelection struct {
+	Spec   string
+	Source string
+	// Minimum selects the newest installed version not older than Spec, e.g. for a go directive
+	Minimum bool
+}
+
+// String describes the selected version, e.g. go >= 1.22.0.
+func (s VersionSelection) String() string {
+	if s.Minimum {
+		return "go >= " + NormalizeSpec(s.Spec)
+	}
+	return "go " + NormalizeSpec(s.Spec)
+}
+
+// FindVersionFile walks up from dir to the first directory holding a .go-version
+// or .tool-versions file naming a Go version. A .go-version file takes precedence
+// over a .tool-versions file of the same directory.
+func FindVersionFile(dir string) (VersionSelection, bool, error) {
+	for {
+		for _, name := range []string{GoVersionFile, ToolVersionFile} {
+			file := filepath.Join(dir, name)
+			spec, err := readVersionFile(file)
+			if err != nil {
+				return VersionSelection{}, false, err
+			}
+			if spec != "" {
+				return VersionSelection{Spec: spec, Source: file}, true, nil
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return VersionSelection{}, false, nil
+		}
+		dir = parent
+	}
+}
+
+// readVersionFile returns the Go version spec of a .go-version or .tool-versions
+// file, or an empty string when the file does not exist or names no Go version.
+func readVersionFile(file string) (string, error) {
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("failed to read %s: %v", file, err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		// .go-version holds the version, .tool-versions a "golang <version>" line per tool
+		if filepath.Base(file) == GoVersionFile {
+			return fields[0], nil
+		}
+		if (fields[0] == "golang" || fields[0] == "go") && len(fields) > 1 {
+			return fields[1], nil
+		}
+	}
+	return "", scanner.Err()
+}
+
+// SelectVersion returns the Go version selected for dir: GOVM_VERSION when set,
+// then the version the project selects, then the global version ~/.govm/current points to.
+func (d *Directory) SelectVersion(dir string) (VersionSelection, error) {
+	if spec := os.Getenv("GOVM_VERSION"); spec != "" {
+		return VersionSelection{Spec: spec, Source: "GOVM_VERSION"}, nil
+	}
+	selection, found, err := SelectProjectVersion(dir)
+	if err != nil || found {
+		return selection, err
+	}
+	current, err := d.Current()
+	if err != nil {
+		return VersionSelection{}, err
+	}
+	return VersionSelection{Spec: current, Source: d.CurrentDir}, nil
+}
+
+// ResolveSelection returns the installed Go version matching a selection.
+func ResolveSelection(selection VersionSelection) (string, error) {
+	b := Binary{}
+	if err := b.GetAllVersions(); err != nil {
+		return "", err
+	}
+	if selection.Minimum {
+		return b.ResolveMinimum(selection)
+	}
+	version, err := b.ResolveVersion(selection.Spec)
+	if err != nil {
+		return "", fmt.Errorf(
+			"%s selected by %s is not installed. Run 'govm install %s'",
+			selection, selection.Source, NormalizeSpec(selection.Spec),
+		)
+	}
+	return version, nil
+}
+
+// RunShim runs a Go command of the version selected for the working directory.
+func RunShim(name string, args []string) error {
+	dir := Directory{}
+	if err := dir.GetDirectories(); err != nil {
+		return err
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	selection, err := dir.SelectVersion(wd)
+	if err != nil {
+		return err
+	}
+	config := Config{}
+	if err := config.Load(); err != nil {
+		return err
+	}
+	version, err := ResolveOrInstall(selection, config.AutoInstall)
+	if err != nil {
+		return err
+	}
+
+	tool := filepath.Join(dir.ConfigDir, "go"+version, "bin", name)
+	if runtime.GOOS == "windows" {
+		tool += ".exe"
+	}
+	return ExecCommand(tool, args)
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// ExecCommand replaces the govm process with the command at path.
+func ExecCommand(path string, args []string) error {
+	argv := append([]string{path}, args...)
+	if err := syscall.Exec(path, argv, os.Environ()); err != nil {
+		return fmt.Errorf("failed to run %s: %v", path, err)
+	}
+	return nil
+}
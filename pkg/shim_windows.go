@@ -0,0 +1,29 @@
+//go:build windows
+
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// ExecCommand runs the command at path and exits with its exit code,
+// since Windows cannot replace the govm process.
+func ExecCommand(path string, args []string) error {
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.ExitCode())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run %s: %v", path, err)
+	}
+	os.Exit(0)
+	return nil
+}
@@ -0,0 +1,147 @@
+package pkg
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+// The Go release archives are signed with the Google Linux Packages Signing Authority key.
+// The key is committed so signatures are verified offline, go generate refreshes it
+// when Google adds a signing subkey.
+//go:generate curl -fsSL -o keys/golang-release.asc https://dl.google.com/linux/linux_signing_key.pub
+
+//go:embed keys/golang-release.asc
+var releaseKeyring []byte
+
+// releaseKeyFingerprint is the fingerprint of the primary key of the embedded keyring.
+const releaseKeyFingerprint = "EB4C1BFD4F042F6DDDCCEC917721F63BD38B4796"
+
+// loadKeyring returns the keyring read from file, or the embedded Go release key when file is empty.
+func loadKeyring(file string) (openpgp.EntityList, error) {
+	data := releaseKeyring
+	if file != "" {
+		var err error
+		if data, err = os.ReadFile(file); err != nil {
+			return nil, fmt.Errorf("failed to read keyring %s: %v", file, err)
+		}
+	} else if len(bytes.TrimSpace(data)) == 0 {
+		return nil, fmt.Errorf("no Go release key embedded in this build of govm. Use --keyring to provide one")
+	}
+
+	keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid keyring: %v", err)
+	}
+
+	// The embedded keyring may hold other Google keys, only the Go release key is trusted
+	if file == "" {
+		return selectKey(keyring, releaseKeyFingerprint)
+	}
+	return keyring, nil
+}
+
+// selectKey returns the entity of a keyring whose primary key has the given fingerprint.
+func selectKey(keyring openpgp.EntityList, fingerprint string) (openpgp.EntityList, error) {
+	for _, entity := range keyring {
+		if strings.EqualFold(fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint), fingerprint) {
+			return openpgp.EntityList{entity}, nil
+		}
+	}
+	return nil, fmt.Errorf("the Go release key %s is not embedded in this build of govm", fingerprint)
+}
+
+// DownloadSignature downloads the detached signature of the archive next to it as <archive>.asc.
+func (t *Tarball) DownloadSignature(file string) (string, error) {
+	body, err := openURL(t.Url + ".asc")
+	if err != nil {
+		return "", fmt.Errorf("failed to download signature for %s: %w", t.Url, err)
+	}
+	defer func() {
+		_ = body.Close()
+	}()
+
+	signature := file + ".asc"
+	f, err := os.OpenFile(signature, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to create file %s", signature)
+	}
+	_, copyErr := io.Copy(f, io.LimitReader(body, 64<<10))
+	if closeErr := f.Close(); copyErr == nil {
+		copyErr = closeErr
+	}
+	if copyErr != nil {
+		_ = os.Remove(signature)
+		return "", fmt.Errorf("failed to write signature %s", signature)
+	}
+	return signature, nil
+}
+
+// VerifySignature checks the detached PGP signature of an archive against
+// the keyring file, or the embedded Go release key when keyringFile is empty.
+// It returns the identity of the signer.
+func VerifySignature(file, signature, keyringFile string) (string, error) {
+	keyring, err := loadKeyring(keyringFile)
+	if err != nil {
+		return "", err
+	}
+
+	archive, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = archive.Close()
+	}()
+
+	sig, err := os.Open(signature)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = sig.Close()
+	}()
+
+	signer, err := openpgp.CheckArmoredDetachedSignature(keyring, archive, sig, nil)
+	if err != nil {
+		return "", fmt.Errorf("signature verification failed for %s: %v", file, err)
+	}
+	for name := range signer.Identities {
+		return name, nil
+	}
+	return "", nil
+}
+
+// CheckSignature downloads the signature of the downloaded archive and verifies it.
+// The archive and its signature are deleted when the verification fails.
+func (t *Tarball) CheckSignature(keyringFile string) error {
+	file := t.File.Name()
+	signature := file + ".asc"
+	if t.Offline {
+		if _, err := os.Stat(signature); err != nil {
+			return missingFromCache("the signature of "+filepath.Base(file), signature)
+		}
+	} else {
+		var err error
+		if signature, err = t.DownloadSignature(file); err != nil {
+			return err
+		}
+	}
+	signer, err := VerifySignature(file, signature, keyringFile)
+	if err != nil {
+		_ = os.Remove(file)
+		_ = os.Remove(signature)
+		return err
+	}
+	if signer != "" {
+		t.Downloader.Progress.Println(BlackAnsi, fmt.Sprintf("🔏 Signed by %s", signer)+"\n")
+	}
+	t.Downloader.Progress.Println(GreenAnsi, "🔒 Signature verified\n")
+	return nil
+}
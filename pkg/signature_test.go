@@ -0,0 +1,97 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+)
+
+// TestEmbeddedReleaseKey fails the build of a release whose keyring is missing,
+// since --verify-signature could never succeed without it.
+func TestEmbeddedReleaseKey(t *testing.T) {
+	if len(bytes.TrimSpace(releaseKeyring)) == 0 {
+		t.Fatal("pkg/keys/golang-release.asc is empty, run go generate ./pkg and commit the key")
+	}
+	if _, err := loadKeyring(""); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	dir := t.TempDir()
+	entity, err := openpgp.NewEntity("govm test", "", "test@govm.invalid", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var key bytes.Buffer
+	w, err := armor.Encode(&key, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := entity.Serialize(w); err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Close()
+	keyring := filepath.Join(dir, "keyring.asc")
+	if err := os.WriteFile(keyring, key.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	archive := filepath.Join(dir, "go1.22.3.linux-amd64.tar.gz")
+	if err := os.WriteFile(archive, []byte("archive"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var sig bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&sig, entity, bytes.NewReader([]byte("archive")), nil); err != nil {
+		t.Fatal(err)
+	}
+	signature := archive + ".asc"
+	if err := os.WriteFile(signature, sig.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := VerifySignature(archive, signature, keyring)
+	if err != nil {
+		t.Fatalf("VerifySignature returned error: %v", err)
+	}
+	if signer != "govm test <test@govm.invalid>" {
+		t.Errorf("VerifySignature signer = %q", signer)
+	}
+
+	// A tampered archive fails
+	if err := os.WriteFile(archive, []byte("tampered"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifySignature(archive, signature, keyring); err == nil {
+		t.Error("VerifySignature accepted a tampered archive")
+	}
+}
+
+func TestSelectKey(t *testing.T) {
+	var keyring openpgp.EntityList
+	for _, name := range []string{"other", "release"} {
+		entity, err := openpgp.NewEntity(name, "", name+"@govm.invalid", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyring = append(keyring, entity)
+	}
+	fingerprint := fmt.Sprintf("%x", keyring[1].PrimaryKey.Fingerprint)
+
+	selected, err := selectKey(keyring, fingerprint)
+	if err != nil {
+		t.Fatalf("selectKey returned error: %v", err)
+	}
+	if len(selected) != 1 || selected[0] != keyring[1] {
+		t.Errorf("selectKey = %v, want only the release key", selected)
+	}
+	if _, err := selectKey(keyring[:1], fingerprint); err == nil {
+		t.Error("selectKey accepted a keyring without the release key")
+	}
+}
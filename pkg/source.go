@@ -0,0 +1,281 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultSourceRepo is the Go repository source builds are cloned from.
+const DefaultSourceRepo = "https://go.googlesource.com/go"
+
+// SpecTip is the version spec of the tip of the Go repository, its master branch.
+const SpecTip = "tip"
+
+// goVersionConst matches the minor version declared in src/internal/goversion/goversion.go.
+var goVersionConst = regexp.MustCompile(`(?m)^const Version = (\d+)`)
+
+// Source is a git repository of the Go source code that Go versions are built from.
+// Unless Dir points to a local checkout, govm keeps a mirror clone of Repo in ~/.govm/src.
+type Source struct {
+	Repo string
+	Dir  string
+	// Offline builds from the commits already fetched
+	Offline bool
+	mirror  bool
+}
+
+// Sync clones the repository, or fetches its latest commits when it is already cloned.
+func (s *Source) Sync() error {
+	if s.Repo == "" {
+		s.Repo = DefaultSourceRepo
+	}
+
+	// Fetch into a local checkout, whose own branches are left alone
+	if s.Dir != "" {
+		if _, err := s.git("rev-parse", "--git-dir"); err != nil {
+			return fmt.Errorf("%s is not a git checkout of Go: %v", s.Dir, err)
+		}
+		if s.Offline {
+			return nil
+		}
+		BlackPrintln(fmt.Sprintf("🔄 Fetching the latest commits into %s", s.Dir) + "\n")
+		if _, err := s.git("fetch", "--tags", "origin"); err != nil {
+			RedPrintln(fmt.Sprintf("Failed to fetch into %s, building from the commits already fetched: %v", s.Dir, err) + "\n")
+		}
+		return nil
+	}
+
+	dir := Directory{}
+	if err := dir.GetDirectories(); err != nil {
+		return err
+	}
+	s.Dir = filepath.Join(dir.RootDir, "src", "go.git")
+	s.mirror = true
+	if _, err := os.Stat(s.Dir); err == nil {
+		if s.Offline {
+			return nil
+		}
+		BlackPrintln(fmt.Sprintf("🔄 Fetching the latest commits from %s", s.Repo) + "\n")
+		if _, err := s.git("fetch", "--prune", "origin"); err != nil {
+			return fmt.Errorf("failed to fetch %s: %v", s.Repo, err)
+		}
+		return nil
+	}
+	if s.Offline {
+		return fmt.Errorf("the Go source repository is not cloned yet, run the build once without --offline")
+	}
+
+	// Clone into a temporary folder so an interrupted clone is not mistaken for a complete one
+	BlackPrintln(fmt.Sprintf("⚡️Cloning %s, this takes a few minutes", s.Repo) + "\n")
+	if err := os.MkdirAll(filepath.Dir(s.Dir), 0755); err != nil {
+		return fmt.Errorf("unable to create source directory %s", filepath.Dir(s.Dir))
+	}
+	part := s.Dir + ".part"
+	_ = os.RemoveAll(part)
+	cmd := exec.Command("git", "clone", "--mirror", s.Repo, part)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		_ = os.RemoveAll(part)
+		return fmt.Errorf("failed to clone %s: %v", s.Repo, err)
+	}
+	if err := os.Rename(part, s.Dir); err != nil {
+		return fmt.Errorf("failed to move %s into place: %v", part, err)
+	}
+	return nil
+}
+
+// Resolve returns the full hash of the commit a branch, tag or commit hash points to.
+// Branches are looked up on the remote first, so a stale local branch is not built.
+func (s *Source) Resolve(ref string) (string, error) {
+	if strings.EqualFold(ref, SpecTip) {
+		ref = "master"
+	}
+	candidates := []string{ref}
+	if !s.mirror {
+		candidates = []string{"refs/remotes/origin/" + ref, ref}
+	}
+	for _, candidate := range candidates {
+		if sha, err := s.git("rev-parse", "--verify", "--quiet", candidate+"^{commit}"); err == nil {
+			return sha, nil
+		}
+	}
+	return "", fmt.Errorf("no commit matching %q found in %s", ref, s.Dir)
+}
+
+// GoMinor returns the minor version of Go the source code of a commit will become, e.g. 24 for go1.24.
+func (s *Source) GoMinor(sha string) (int, error) {
+	out, err := s.git("show", sha+":src/internal/goversion/goversion.go")
+	if err != nil {
+		return 0, fmt.Errorf("commit %s is not a Go source tree: %v", ShortHash(sha), err)
+	}
+	match := goVersionConst.FindStringSubmatch(out)
+	if match == nil {
+		return 0, fmt.Errorf("unable to read the Go version of commit %s", ShortHash(sha))
+	}
+	return strconv.Atoi(match[1])
+}
+
+// Build exports the source code of a commit into the staging folder of an install,
+// builds it with make.bash using the bootstrap toolchain and commits it.
+func (s *Source) Build(sha string, minor int, bootstrap string, install *Install) error {
+	// Export the commit, the .git folder is not needed to build
+	BlackPrintln(fmt.Sprintf("📦 Exporting %s into %s", ShortHash(sha), install.StagingDir) + "\n")
+	cmd := exec.Command("git", "-C", s.Dir, "archive", "--format=tar.gz", "--prefix=go/", sha)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to run git archive: %v", err)
+	}
+	extractErr := ExtractTarGz(stdout, install.StagingDir)
+	_, _ = io.Copy(io.Discard, stdout)
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("failed to export %s: %v: %s", ShortHash(sha), err, strings.TrimSpace(stderr.String()))
+	}
+	if extractErr != nil {
+		return fmt.Errorf("failed to export %s: %v", ShortHash(sha), extractErr)
+	}
+
+	// Without a .git folder, make.bash takes the version from the VERSION file
+	date, err := s.git("show", "-s", "--format=%ct", sha)
+	if err != nil {
+		return err
+	}
+	seconds, err := strconv.ParseInt(date, 10, 64)
+	if err != nil {
+		return fmt.Errorf("unexpected commit date %q", date)
+	}
+	install.Release = fmt.Sprintf("devel go1.%d-%s %s", minor, ShortHash(sha), time.Unix(seconds, 0).UTC().Format("Mon Jan 2 15:04:05 2006 -0700"))
+	if err := os.WriteFile(filepath.Join(install.StagingDir, "VERSION"), []byte(install.Release+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write VERSION file: %v", err)
+	}
+
+	// Build the toolchain
+	BlackPrintln(fmt.Sprintf("🔨 Building %s with %s", install.Release, bootstrap) + "\n")
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/c", "make.bat")
+	} else {
+		cmd = exec.Command("bash", "make.bash")
+	}
+	cmd.Dir = filepath.Join(install.StagingDir, "src")
+	cmd.Env = append(os.Environ(), "GOROOT=", "GOROOT_BOOTSTRAP="+bootstrap, "GOTOOLCHAIN=local", "GOFLAGS=")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to build %s: %v", ShortHash(sha), err)
+	}
+	return install.Commit()
+}
+
+// git runs a git command in the repository and returns its trimmed output.
+func (s *Source) git(args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", s.Dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s", msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ShortHash returns the abbreviated form of a commit hash.
+func ShortHash(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// refNameChars matches the characters left out of the name of a source build.
+var refNameChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// SourceBuildName returns the version name a build of ref is installed under:
+// tip-<hash> for tip, src-<hash> for a commit hash and src-<ref>-<hash> otherwise.
+func SourceBuildName(ref, sha string) string {
+	short := ShortHash(sha)
+	ref = strings.ToLower(strings.TrimSpace(ref))
+	if ref == SpecTip || ref == "master" {
+		return SpecTip + "-" + short
+	}
+	if len(ref) >= 4 && strings.HasPrefix(sha, ref) {
+		return "src-" + short
+	}
+	for _, prefix := range []string{"refs/heads/", "refs/tags/", "refs/remotes/", "origin/"} {
+		ref = strings.TrimPrefix(ref, prefix)
+	}
+	ref = strings.Trim(refNameChars.ReplaceAllString(ref, "-"), "-.")
+	return "src-" + ref + "-" + short
+}
+
+// bootstrapVersion returns the oldest Go release make.bash accepts to build go1.<minor>:
+// go1.17.13 up to Go 1.21, then the .6 patch of go1.<minor-2> rounded down to an even
+// minor, e.g. go1.22.6 for Go 1.24 and Go 1.25.
+func bootstrapVersion(minor int) GoVersion {
+	if minor < 22 {
+		return GoVersion{Major: 1, Minor: 17, Patch: 13}
+	}
+	return GoVersion{Major: 1, Minor: (minor - 2) &^ 1, Patch: 6}
+}
+
+// BootstrapToolchain returns the Go installation used to build go1.<minor> from source:
+// GOROOT_BOOTSTRAP when set, otherwise the newest installed stable release recent enough.
+func BootstrapToolchain(minor int) (string, error) {
+	if bootstrap := os.Getenv("GOROOT_BOOTSTRAP"); bootstrap != "" {
+		return bootstrap, nil
+	}
+
+	required := bootstrapVersion(minor)
+	dir := Directory{}
+	if err := dir.GetDirectories(); err != nil {
+		return "", err
+	}
+	b := Binary{}
+	if err := b.GetAllVersions(); err == nil {
+		for _, name := range b.Versions {
+			version, err := ParseGoVersion(name)
+			if err == nil && version.Stable() && version.Compare(required) >= 0 {
+				return filepath.Join(dir.ConfigDir, name), nil
+			}
+		}
+	}
+	return "", fmt.Errorf(
+		"building go1.%d from source needs go%s or newer to bootstrap. Install it with 'govm install %s' or set GOROOT_BOOTSTRAP",
+		minor, required, required.MinorLine(),
+	)
+}
+
+// NewestSourceBuild returns the name of the most recently built of the installed
+// versions whose name starts with prefix, e.g. tip-.
+func NewestSourceBuild(versionDir string, names []string, prefix string) (string, bool) {
+	newest, newestTime := "", time.Time{}
+	for _, name := range names {
+		if !strings.HasPrefix(name, "go"+prefix) {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(versionDir, name))
+		if err != nil {
+			continue
+		}
+		if newest == "" || info.ModTime().After(newestTime) {
+			newest, newestTime = name, info.ModTime()
+		}
+	}
+	return strings.TrimPrefix(newest, "go"), newest != ""
+}
@@ -0,0 +1,28 @@
+package pkg
+
+import (
+	"testing"
+)
+
+func TestBootstrapVersion(t *testing.T) {
+	tests := []struct {
+		minor int
+		want  string
+	}{
+		{minor: 21, want: "1.17.13"},
+		{minor: 22, want: "1.20.6"},
+		{minor: 23, want: "1.20.6"},
+		{minor: 24, want: "1.22.6"},
+		{minor: 25, want: "1.22.6"},
+		{minor: 26, want: "1.24.6"},
+	}
+	for _, tt := range tests {
+		if got := bootstrapVersion(tt.minor); got.String() != tt.want {
+			t.Errorf("bootstrapVersion(%d) = %s, want %s", tt.minor, got, tt.want)
+		}
+	}
+	// A release of the required minor line older than its .6 patch cannot bootstrap
+	if MustParseGoVersion("1.22.5").Compare(bootstrapVersion(24)) >= 0 {
+		t.Error("go1.22.5 accepted to bootstrap Go 1.24")
+	}
+}
@@ -0,0 +1,120 @@
+package pkg
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// resetDir removes the content of a directory.
+func resetDir(dir string) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	return os.MkdirAll(dir, 0755)
+}
+
+// canStream reports whether an archive can be extracted while it downloads:
+// only fresh downloads of .tar.gz archives over http(s) are streamed.
+func canStream(url, dest string) bool {
+	if !strings.HasSuffix(url, ".tar.gz") || strings.HasPrefix(url, "file:") {
+		return false
+	}
+	_, err := os.Stat(dest + ".part")
+	return os.IsNotExist(err)
+}
+
+// Stream downloads url into dest and extracts it into staging at the same time.
+// The data is written to dest.part, which is renamed to dest once complete.
+// It returns the SHA-256 checksum of the downloaded archive. On failure the
+// .part file is kept so the download can be resumed.
+func (d *Downloader) Stream(url, dest, staging string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create HTTP request")
+	}
+	resp, err := d.client().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return "", &HTTPStatusError{URL: url, StatusCode: resp.StatusCode}
+	}
+
+	part := dest + ".part"
+	f, err := os.OpenFile(part, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return "", err
+	}
+
+	// Tee the body into the cache file, the checksum and the extractor
+	hash := sha256.New()
+	bar := d.Progress.NewBar(resp.ContentLength)
+	body := newIdleReader(resp.Body, d.idleTimeout(), cancel)
+	tee := io.TeeReader(body, io.MultiWriter(f, hash, bar))
+	streamErr := ExtractTarGz(tee, staging)
+	if streamErr == nil {
+		// Read what follows the end of the tar stream so the whole file is cached and hashed
+		_, streamErr = io.Copy(io.Discard, tee)
+	}
+	body.stop()
+	if closeErr := f.Close(); streamErr == nil {
+		streamErr = closeErr
+	}
+	if streamErr != nil {
+		if body.expired() {
+			return "", fmt.Errorf("%w for %s", errIdleTimeout, d.idleTimeout())
+		}
+		return "", streamErr
+	}
+
+	if err := os.Rename(part, dest); err != nil {
+		return "", fmt.Errorf("failed to move %s into the cache: %v", part, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// streamFromMirror downloads the archive into file while extracting it into the
+// staging directory of the tarball. It reports false when the archive has to be
+// downloaded and extracted separately instead.
+func (t *Tarball) streamFromMirror(mirror, version, file string) (bool, error) {
+	if t.StagingDir == "" || !canStream(t.Url, file) {
+		return false, nil
+	}
+
+	t.Downloader.Progress.Println(BlackAnsi, fmt.Sprintf("⚡️Downloading and extracting go%s from %s", version, mirror)+"\n")
+	checksum, err := t.Downloader.Stream(t.Url, file, t.StagingDir)
+	if err != nil {
+		if resetErr := resetDir(t.StagingDir); resetErr != nil {
+			return false, resetErr
+		}
+		if isNotFound(err) {
+			return false, err
+		}
+		// Resume the download and extract the archive afterwards
+		t.Downloader.Progress.Println(RedAnsi, fmt.Sprintf("\nStreaming go%s failed (%v), resuming the download", version, err)+"\n")
+		return false, nil
+	}
+
+	if !strings.EqualFold(checksum, t.Checksum) {
+		_ = os.Remove(file)
+		if resetErr := resetDir(t.StagingDir); resetErr != nil {
+			return false, resetErr
+		}
+		return false, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", file, t.Checksum, checksum)
+	}
+	t.Extracted = true
+	t.Downloaded = true
+	return true, nil
+}
@@ -0,0 +1,144 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/sumdb"
+)
+
+// knownSumDBKeys holds the verifier keys of the public checksum databases.
+var knownSumDBKeys = map[string]string{
+	"sum.golang.org":       "sum.golang.org+033de0ae+Ac4zctda0e5eza+HJyk9SxEdh+s3Ux18htTTAD8OuAn8",
+	"sum.golang.google.cn": "sum.golang.org+033de0ae+Ac4zctda0e5eza+HJyk9SxEdh+s3Ux18htTTAD8OuAn8",
+}
+
+// sumDBOps implements sumdb.ClientOps, caching the database tiles under ~/.govm/.cache/sumdb.
+type sumDBOps struct {
+	name     string
+	key      string
+	url      string
+	cacheDir string
+	mu       sync.Mutex
+}
+
+// newSumDBClient returns a checksum database client for a GOSUMDB setting, which is
+// either a known database name or "<key> [url]". Like the go command, the database
+// is reached through the first module proxy supporting it, or directly otherwise.
+func newSumDBClient(gosumdb string, proxies []proxyEntry) (*sumdb.Client, error) {
+	fields := strings.Fields(gosumdb)
+	if len(fields) == 0 || len(fields) > 2 {
+		return nil, fmt.Errorf("invalid GOSUMDB %q", gosumdb)
+	}
+
+	ops := &sumDBOps{key: fields[0]}
+	if key, ok := knownSumDBKeys[fields[0]]; ok {
+		ops.key = key
+		ops.name = fields[0]
+	} else {
+		ops.name, _, _ = strings.Cut(fields[0], "+")
+	}
+	if ops.name == "" {
+		return nil, fmt.Errorf("invalid GOSUMDB %q", gosumdb)
+	}
+
+	if len(fields) == 2 {
+		ops.url = strings.TrimSuffix(fields[1], "/")
+	} else {
+		ops.url = "https://" + ops.name
+		for _, entry := range proxies {
+			if entry.url == "off" || entry.url == directToolchainProxy {
+				break
+			}
+			body, err := openURL(entry.url + "/sumdb/" + ops.name + "/supported")
+			if err == nil {
+				_ = body.Close()
+				ops.url = entry.url + "/sumdb/" + ops.name
+				break
+			}
+		}
+	}
+
+	dir := Directory{}
+	if err := dir.GetDirectories(); err != nil {
+		return nil, err
+	}
+	ops.cacheDir = filepath.Join(dir.CacheDir, "sumdb")
+	return sumdb.NewClient(ops), nil
+}
+
+func (o *sumDBOps) ReadRemote(path string) ([]byte, error) {
+	body, err := openURL(o.url + path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = body.Close()
+	}()
+	return io.ReadAll(body)
+}
+
+func (o *sumDBOps) ReadConfig(file string) ([]byte, error) {
+	if file == "key" {
+		return []byte(o.key), nil
+	}
+	data, err := os.ReadFile(filepath.Join(o.cacheDir, filepath.FromSlash(file)))
+	if os.IsNotExist(err) {
+		// Start with an empty signed tree
+		return nil, nil
+	}
+	return data, err
+}
+
+func (o *sumDBOps) WriteConfig(file string, old, new []byte) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	current, err := o.ReadConfig(file)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(current, old) {
+		return sumdb.ErrWriteConflict
+	}
+	return writeFileAtomic(filepath.Join(o.cacheDir, filepath.FromSlash(file)), new)
+}
+
+func (o *sumDBOps) ReadCache(file string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(o.cacheDir, filepath.FromSlash(file)))
+}
+
+func (o *sumDBOps) WriteCache(file string, data []byte) {
+	_ = writeFileAtomic(filepath.Join(o.cacheDir, filepath.FromSlash(file)), data)
+}
+
+func (o *sumDBOps) Log(msg string) {}
+
+func (o *sumDBOps) SecurityError(msg string) {
+	RedPrintln(msg + "\n")
+}
+
+// writeFileAtomic writes data to a temporary file and renames it to file.
+func writeFileAtomic(file string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".tmp*")
+	if err != nil {
+		return err
+	}
+	_, writeErr := tmp.Write(data)
+	if closeErr := tmp.Close(); writeErr == nil {
+		writeErr = closeErr
+	}
+	if writeErr != nil {
+		_ = os.Remove(tmp.Name())
+		return writeErr
+	}
+	return os.Rename(tmp.Name(), file)
+}
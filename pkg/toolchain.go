@@ -0,0 +1,346 @@
+package pkg
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+// ToolchainModule is the module Go distributes its toolchains as.
+const ToolchainModule = "golang.org/toolchain"
+
+// directToolchainProxy serves the toolchain module when GOPROXY says "direct",
+// since golang.org/toolchain has no version control repository.
+const directToolchainProxy = "https://go.dev/dl/mod"
+
+// ToolchainVersion returns the module version of a Go toolchain for a distribution
+// platform, e.g. v0.0.1-go1.22.3.linux-arm for linux-armv6l.
+func ToolchainVersion(version, platform string) string {
+	return fmt.Sprintf("v0.0.1-go%s.%s", version, ModulePlatform(platform))
+}
+
+// goEnv returns the value of a go environment setting, looking at the
+// environment first and then at the go env file written by 'go env -w'.
+func goEnv(key string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+
+	file := os.Getenv("GOENV")
+	if file == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return ""
+		}
+		file = filepath.Join(dir, "go", "env")
+	}
+	if file == "off" {
+		return ""
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return ""
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(scanner.Text(), "=")
+		if ok && strings.TrimSpace(name) == key {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// proxyEntry is one entry of GOPROXY.
+type proxyEntry struct {
+	url string
+	// fallbackOnError is true when the entry is followed by "|", in which case
+	// any error moves on to the next entry, not only a missing module.
+	fallbackOnError bool
+}
+
+// ModuleProxy downloads Go toolchains through the module proxy protocol,
+// honoring GOPROXY, GOSUMDB, GONOSUMDB and GOPRIVATE like 'go mod download'.
+type ModuleProxy struct {
+	Proxies    []proxyEntry
+	SumDB      string
+	NoSumDB    string
+	Downloader Downloader
+	// Offline only uses the toolchains already in the cache
+	Offline bool
+}
+
+// Load reads the proxy settings from the go environment.
+func (p *ModuleProxy) Load() error {
+	goproxy := goEnv("GOPROXY")
+	if goproxy == "" {
+		goproxy = "https://proxy.golang.org,direct"
+	}
+
+	p.Proxies = nil
+	for goproxy != "" {
+		var entry string
+		fallbackOnError := false
+		if i := strings.IndexAny(goproxy, ",|"); i >= 0 {
+			entry = goproxy[:i]
+			fallbackOnError = goproxy[i] == '|'
+			goproxy = goproxy[i+1:]
+		} else {
+			entry, goproxy = goproxy, ""
+		}
+		entry = strings.TrimSpace(entry)
+		switch entry {
+		case "":
+			continue
+		case "off":
+			if len(p.Proxies) == 0 {
+				return fmt.Errorf("module lookups disabled by GOPROXY=off")
+			}
+		case "direct":
+			entry = directToolchainProxy
+		}
+		p.Proxies = append(p.Proxies, proxyEntry{url: strings.TrimSuffix(entry, "/"), fallbackOnError: fallbackOnError})
+		if entry == "off" {
+			break
+		}
+	}
+	if len(p.Proxies) == 0 {
+		return fmt.Errorf("no module proxy configured in GOPROXY")
+	}
+
+	p.SumDB = goEnv("GOSUMDB")
+	if p.SumDB == "" {
+		p.SumDB = "sum.golang.org"
+	}
+	p.NoSumDB = goEnv("GONOSUMDB")
+	if p.NoSumDB == "" {
+		p.NoSumDB = goEnv("GOPRIVATE")
+	}
+	return nil
+}
+
+// try calls fn with each proxy URL in GOPROXY order until one succeeds.
+func (p *ModuleProxy) try(fn func(proxy string) error) error {
+	var err error
+	for _, entry := range p.Proxies {
+		if entry.url == "off" {
+			return fmt.Errorf("module lookups disabled by GOPROXY=off: %w", err)
+		}
+		err = fn(entry.url)
+		if err == nil || (!entry.fallbackOnError && !isNotFound(err) && !isGone(err)) {
+			return err
+		}
+	}
+	return err
+}
+
+// isGone reports whether a proxy answered 410 Gone, which like 404 moves on to the next proxy.
+func isGone(err error) bool {
+	var statusErr *HTTPStatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == 410
+}
+
+// Releases lists the toolchains the proxy offers for a platform as releases.
+// The list is cached, offline it is read from the cache, or else from the
+// cached release index.
+func (p *ModuleProxy) Releases(platform string) ([]Release, error) {
+	file, err := cachedToolchainsFile(platform)
+	if err != nil {
+		return nil, err
+	}
+	if p.Offline {
+		return cachedToolchains(file)
+	}
+
+	path, err := module.EscapePath(ToolchainModule)
+	if err != nil {
+		return nil, err
+	}
+
+	var releases []Release
+	err = p.try(func(proxy string) error {
+		body, err := openURL(proxy + "/" + path + "/@v/list")
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = body.Close()
+		}()
+
+		releases = nil
+		suffix := "." + ModulePlatform(platform)
+		scanner := bufio.NewScanner(body)
+		for scanner.Scan() {
+			version := strings.TrimSpace(scanner.Text())
+			if !strings.HasPrefix(version, "v0.0.1-go") || !strings.HasSuffix(version, suffix) {
+				continue
+			}
+			goVersion, err := ParseGoVersion(strings.TrimSuffix(strings.TrimPrefix(version, "v0.0.1-"), suffix))
+			if err != nil {
+				continue
+			}
+			releases = append(releases, Release{Version: "go" + goVersion.String(), Stable: goVersion.Stable()})
+		}
+		return scanner.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Go toolchains from the module proxy: %v. Use --offline to work from the cache", err)
+	}
+
+	// Failing to save the list only means offline mode keeps using an older copy
+	if data, err := json.Marshal(releases); err == nil {
+		_ = writeFileAtomic(file, data)
+	}
+	return releases, nil
+}
+
+// cachedToolchainsFile returns where the toolchains listed for a platform are kept for offline use.
+func cachedToolchainsFile(platform string) (string, error) {
+	dir := Directory{}
+	if err := dir.GetDirectories(); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir.CacheDir, "toolchains-"+ModulePlatform(platform)+".json"), nil
+}
+
+// cachedToolchains reads the toolchains listed by the last successful Releases call,
+// falling back to the releases of the cached release index.
+func cachedToolchains(file string) ([]Release, error) {
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		index := ReleaseIndex{}
+		if err := index.LoadCached(); err != nil {
+			return nil, fmt.Errorf("no cached toolchain list (%s is missing). Run 'govm install --module-proxy' once with network access", file)
+		}
+		return index.Releases, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read cached toolchain list %s: %v", file, err)
+	}
+	var releases []Release
+	if err := json.Unmarshal(data, &releases); err != nil {
+		return nil, fmt.Errorf("invalid cached toolchain list %s: %v", file, err)
+	}
+	return releases, nil
+}
+
+// DownloadToolchain downloads the toolchain module zip of a Go version for a platform
+// into cachePath and verifies it against the checksum database. It reports false when
+// the zip was taken from the cache in offline mode.
+func (p *ModuleProxy) DownloadToolchain(version, platform, cachePath string) (string, bool, error) {
+	vers := ToolchainVersion(version, platform)
+	path, err := module.EscapePath(ToolchainModule)
+	if err != nil {
+		return "", false, err
+	}
+	escVers, err := module.EscapeVersion(vers)
+	if err != nil {
+		return "", false, err
+	}
+
+	file := filepath.Join(cachePath, fmt.Sprintf("go%s.%s.toolchain.zip", version, platform))
+	if p.Offline {
+		// The checksum database cannot be reached, the toolchain was verified when it was downloaded
+		if _, err := os.Stat(file); err != nil {
+			return "", false, missingFromCache(fmt.Sprintf("toolchain go%s for %s", version, platform), file)
+		}
+		p.Downloader.Progress.Println(BlackAnsi, fmt.Sprintf("📦 Using cached %s@%s (offline)", ToolchainModule, vers)+"\n")
+		return file, false, nil
+	}
+	err = p.try(func(proxy string) error {
+		p.Downloader.Progress.Println(BlackAnsi, fmt.Sprintf("⚡️Downloading %s@%s from %s", ToolchainModule, vers, proxy)+"\n")
+		return p.Downloader.Download(proxy+"/"+path+"/@v/"+escVers+".zip", file)
+	})
+	if isNotFound(err) || isGone(err) {
+		return "", false, fmt.Errorf("no toolchain go%s found for %s on the module proxy", version, platform)
+	} else if err != nil {
+		return "", false, err
+	}
+
+	if err := p.verify(file, vers); err != nil {
+		_ = os.Remove(file)
+		return "", false, err
+	}
+	return file, true, nil
+}
+
+// verify checks the hash of a downloaded toolchain zip against the checksum database.
+func (p *ModuleProxy) verify(file, vers string) error {
+	hash, err := dirhash.HashZip(file, dirhash.Hash1)
+	if err != nil {
+		return fmt.Errorf("failed to hash %s: %v", file, err)
+	}
+
+	if p.SumDB == "off" || module.MatchPrefixPatterns(p.NoSumDB, ToolchainModule) {
+		p.Downloader.Progress.Println(RedAnsi, fmt.Sprintf("Checksum database disabled, not verifying %s@%s", ToolchainModule, vers)+"\n")
+		return nil
+	}
+
+	client, err := newSumDBClient(p.SumDB, p.Proxies)
+	if err != nil {
+		return err
+	}
+	lines, err := client.Lookup(ToolchainModule, vers)
+	if err != nil {
+		return fmt.Errorf("failed to verify %s@%s against the checksum database: %v", ToolchainModule, vers, err)
+	}
+
+	want := ToolchainModule + " " + vers + " " + hash
+	for _, line := range lines {
+		if line == want {
+			p.Downloader.Progress.Println(GreenAnsi, fmt.Sprintf("🔒 Checksum verified against %s", p.SumDB)+"\n")
+			return nil
+		}
+	}
+	return fmt.Errorf("checksum mismatch for %s@%s: downloaded %s is not the one recorded in %s", ToolchainModule, vers, hash, p.SumDB)
+}
+
+// ExtractToolchain extracts a toolchain module zip into dest, dropping the module prefix.
+func ExtractToolchain(file, version, platform, dest string) error {
+	r, err := zip.OpenReader(file)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", file, err)
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+
+	prefix := ToolchainModule + "@" + ToolchainVersion(version, platform) + "/"
+	for _, f := range r.File {
+		name, ok := strings.CutPrefix(f.Name, prefix)
+		if !ok || name == "" {
+			return fmt.Errorf("unexpected file %s in %s", f.Name, file)
+		}
+		target, err := safeJoin(dest, name)
+		if err != nil {
+			return err
+		}
+		if strings.HasSuffix(name, "/") {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Module zips do not record file modes, make the tools executable like the go command does
+		mode := os.FileMode(0644)
+		if strings.HasPrefix(name, "bin/") || strings.HasPrefix(name, "pkg/tool/") {
+			mode = 0755
+		}
+		if err := extractZipFile(f, target, mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
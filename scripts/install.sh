@@ -19,6 +19,11 @@ case "${ARCH}" in
     x86_64)  ARCH="amd64" ;;
     aarch64|arm64) ARCH="arm64" ;;
     i386|i686) ARCH="386" ;;
+    armv6l|armv7l) ARCH="arm" ;;
+    ppc64le) ARCH="ppc64le" ;;
+    s390x) ARCH="s390x" ;;
+    riscv64) ARCH="riscv64" ;;
+    loongarch64) ARCH="loong64" ;;
     *)
         echo -e "${RED}Unsupported architecture: ${ARCH}${NC}"
         echo "Please submit an issue at: https://github.com/emmadal/govm/issues"